@@ -0,0 +1,586 @@
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Loader loads the documents external references point to
+type Loader interface {
+	// Load returns the content of the document at uri, which is already resolved against the referencing document
+	Load(uri string) ([]byte, error)
+}
+
+// LoaderFunc adapts a function to the Loader interface
+type LoaderFunc func(uri string) ([]byte, error)
+
+func (f LoaderFunc) Load(uri string) ([]byte, error) {
+	return f(uri)
+}
+
+// FileLoader loads paths and file:// urls from the file system, resolving relative paths against Dir
+type FileLoader struct {
+	Dir string
+}
+
+func (l FileLoader) Load(uri string) ([]byte, error) {
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "" && u.Scheme != "file" {
+		return nil, errors.New("cannot load " + uri + ": unsupported scheme " + u.Scheme)
+	}
+
+	p := filepath.FromSlash(u.Path)
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(l.Dir, p)
+	}
+
+	return ioutil.ReadFile(p)
+}
+
+// Bundle returns a copy of doc in which every external reference points to a schema in components/schemas:
+// the targets of external references are loaded with loader and added to components/schemas, named after the
+// last reference of their pointer (or their document, if the pointer is empty), and their own references are
+// bundled as well. Local references are left untouched
+func Bundle(doc *DocumentSpec1, loader Loader) (*DocumentSpec1, error) {
+
+	out, ext, err := newExternalRefs(doc, loader)
+	if err != nil {
+		return nil, err
+	}
+
+	if out.Components == nil {
+		out.Components = &Components{}
+	}
+
+	if out.Components.Schemas == nil {
+		out.Components.Schemas, _ = NewRegistry(newPointerFromRefs([]string{"components", "schemas"}))
+	}
+
+	b := &bundler{ext: ext, schemas: out.Components.Schemas, names: map[string]string{}, taken: map[string]bool{}}
+
+	for _, ptr := range b.schemas.Schemas() {
+		b.taken[ptr.Refs()[len(ptr.Refs())-1]] = true
+	}
+
+	err = out.contentDescriptors(func(cd *ContentDescriptor) error {
+		if ptr, ok := cd.Schema.(ExternalPointer); ok {
+			name, err := b.component("", ptr.Document()+"#"+fragmentEscape(ptr.String()))
+			if err != nil {
+				return err
+			}
+
+			cd.Schema = appendRefs(b.schemas.unmarshalFrom, name)
+			return nil
+		}
+
+		if cd.InlineSchema == nil {
+			return nil
+		}
+
+		sch, err := rewriteSchema(cd.InlineSchema, func(v interface{}) (interface{}, bool, error) {
+			return b.rewrite(v, "")
+		})
+		if err == nil && sch != nil {
+			cd.InlineSchema = sch
+		}
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = rewriteRegistry(out.Components.Schemas, func(v interface{}) (interface{}, bool, error) {
+		return b.rewrite(v, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// Dereference returns a copy of doc in which every reference, local or external, is replaced by the value it
+// points to; the schemas of content descriptors become inline schemas. Circular references cannot be
+// inlined and make Dereference fail
+func Dereference(doc *DocumentSpec1, loader Loader) (*DocumentSpec1, error) {
+
+	out, ext, err := newExternalRefs(doc, loader)
+	if err != nil {
+		return nil, err
+	}
+
+	if out.Methods, err = out.ResolveMethods(); err != nil {
+		return nil, err
+	}
+
+	inline := func(v interface{}) (interface{}, bool, error) {
+		v, err := ext.inline(v, "", map[string]bool{})
+		return v, true, err
+	}
+
+	err = out.contentDescriptors(func(cd *ContentDescriptor) error {
+
+		var sch interface{} = cd.InlineSchema
+		if cd.Schema != nil {
+			sch = cd.Schema
+		}
+
+		if sch == nil {
+			return nil
+		}
+
+		inlined, err := rewriteSchema(sch, inline)
+		if err != nil {
+			return errors.New("content descriptor " + cd.Name + ": " + err.Error())
+		}
+
+		cd.Schema, cd.InlineSchema = nil, inlined
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if out.Components != nil && out.Components.Schemas != nil {
+		if err := rewriteRegistry(out.Components.Schemas, inline); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// externalRefs resolves references against the document being processed and the documents it references
+type externalRefs struct {
+	loader Loader
+	// docs holds the decoded documents by uri, the document being processed has an empty uri
+	docs map[string]interface{}
+}
+
+// newExternalRefs returns a copy of doc, and an externalRefs resolving local references against doc
+func newExternalRefs(doc *DocumentSpec1, loader Loader) (*DocumentSpec1, *externalRefs, error) {
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var root interface{}
+
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, nil, err
+	}
+
+	out, err := ParseDocument(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return out, &externalRefs{loader: loader, docs: map[string]interface{}{"": root}}, nil
+}
+
+// resolve resolves ref in the document at base, returning the absolute reference, the uri of the document
+// the target belongs to, and the decoded target
+func (e *externalRefs) resolve(base, ref string) (abs, uri string, target interface{}, err error) {
+
+	uri, fragment := ref, ""
+	if i := strings.Index(ref, "#"); i >= 0 {
+		uri, fragment = ref[:i], ref[i:]
+	}
+
+	if uri == "" {
+		uri = base
+	} else {
+		uri = resolveURI(base, uri)
+	}
+
+	ptr, err := NewPointer("#" + strings.TrimPrefix(fragment, "#"))
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	doc, ok := e.docs[uri]
+	if !ok {
+		if e.loader == nil {
+			return "", "", nil, errors.New("cannot load " + uri + ": no loader")
+		}
+
+		data, err := e.loader.Load(uri)
+		if err != nil {
+			return "", "", nil, err
+		}
+
+		if doc, err = decodeExternal(uri, data); err != nil {
+			return "", "", nil, errors.New("cannot decode " + uri + ": " + err.Error())
+		}
+
+		e.docs[uri] = doc
+	}
+
+	abs = uri + "#" + fragmentEscape(ptr.String())
+
+	target, _, err = evalRefs(doc, ptr.Refs())
+	if err != nil {
+		return "", "", nil, errors.New("cannot resolve reference " + abs + ": " + err.Error())
+	}
+
+	return abs, uri, target, nil
+}
+
+// inline replaces every reference in v, a value of the document at base, with the value it points to;
+// stack holds the references being inlined, to detect cycles
+func (e *externalRefs) inline(v interface{}, base string, stack map[string]bool) (interface{}, error) {
+
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := RefOf(node); ok {
+			abs, uri, target, err := e.resolve(base, ref)
+			if err != nil {
+				return nil, err
+			}
+
+			if stack[abs] {
+				return nil, errors.New("circular reference " + abs)
+			}
+
+			stack[abs] = true
+			defer delete(stack, abs)
+
+			return e.inline(target, uri, stack)
+		}
+
+		out := make(map[string]interface{}, len(node))
+
+		for k, item := range node {
+			inlined, err := e.inline(item, base, stack)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = inlined
+		}
+
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(node))
+
+		for i, item := range node {
+			inlined, err := e.inline(item, base, stack)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = inlined
+		}
+
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+type bundler struct {
+	ext     *externalRefs
+	schemas *SchemaRegistry
+	// names maps the absolute external references bundled so far to their component name
+	names map[string]string
+	taken map[string]bool
+}
+
+// rewrite replaces the external references in v, a value of the document at base, with references to
+// components/schemas; it reports whether anything changed
+func (b *bundler) rewrite(v interface{}, base string) (interface{}, bool, error) {
+
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := RefOf(node); ok && (base != "" || !strings.HasPrefix(ref, "#")) {
+			name, err := b.component(base, ref)
+			if err != nil {
+				return nil, false, err
+			}
+
+			out := make(map[string]interface{}, len(node))
+			for k, item := range node {
+				out[k] = item
+			}
+			out["$ref"] = "#" + fragmentEscape(appendRefs(b.schemas.unmarshalFrom, name).String())
+
+			return out, true, nil
+		}
+
+		changed := false
+
+		// keys are sorted so that components are always added in the same order
+		for _, k := range sortedKeys(node) {
+			rewritten, ok, err := b.rewrite(node[k], base)
+			if err != nil {
+				return nil, false, err
+			}
+
+			if ok {
+				if !changed {
+					node = copyObject(node)
+					changed = true
+				}
+				node[k] = rewritten
+			}
+		}
+
+		return node, changed, nil
+	case []interface{}:
+		changed := false
+
+		for i, item := range node {
+			rewritten, ok, err := b.rewrite(item, base)
+			if err != nil {
+				return nil, false, err
+			}
+
+			if ok {
+				if !changed {
+					node = append([]interface{}{}, node...)
+					changed = true
+				}
+				node[i] = rewritten
+			}
+		}
+
+		return node, changed, nil
+	default:
+		return v, false, nil
+	}
+}
+
+// component adds the target of ref, a reference in the document at base, to components/schemas
+// unless it was already added, and returns its name
+func (b *bundler) component(base, ref string) (string, error) {
+
+	abs, uri, target, err := b.ext.resolve(base, ref)
+	if err != nil {
+		return "", err
+	}
+
+	if name, ok := b.names[abs]; ok {
+		return name, nil
+	}
+
+	name := componentName(abs)
+	for i := 2; b.taken[name]; i++ {
+		name = fmt.Sprintf("%s_%d", componentName(abs), i)
+	}
+
+	// the component is added before its target is rewritten, so that circular references end here
+	// and components referencing others come first
+	b.names[abs], b.taken[name] = name, true
+
+	ptr := appendRefs(b.schemas.unmarshalFrom, name)
+
+	b.schemas.mu.Lock()
+	b.schemas.setSchema(ptr, NewSchema())
+	b.schemas.mu.Unlock()
+
+	rewritten, _, err := b.rewrite(target, uri)
+	if err != nil {
+		return "", err
+	}
+
+	sch, err := rewriteSchema(rewritten, nil)
+	if err != nil {
+		return "", errors.New("cannot bundle " + abs + ": " + err.Error())
+	}
+
+	return name, b.schemas.Replace(ptr, sch)
+}
+
+// componentName names the component of an absolute reference after the last reference of its pointer,
+// or after its document if the pointer is empty
+func componentName(abs string) string {
+
+	i := strings.Index(abs, "#")
+
+	if ptr, err := NewPointer(abs[i:]); err == nil && len(ptr.Refs()) > 0 {
+		return ptr.Refs()[len(ptr.Refs())-1]
+	}
+
+	name := path.Base(abs[:i])
+
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// resolveURI resolves ref against the uri of the document it appears in
+func resolveURI(base, ref string) string {
+
+	if u, err := url.Parse(ref); err == nil && (u.Scheme != "" || strings.HasPrefix(ref, "/")) {
+		return ref
+	}
+
+	if u, err := url.Parse(base); err == nil && u.Scheme != "" {
+		r, err := url.Parse(ref)
+		if err == nil {
+			return u.ResolveReference(r).String()
+		}
+	}
+
+	return path.Join(path.Dir(base), ref)
+}
+
+// decodeExternal decodes a json document, or a yaml one if uri has a .yaml or .yml extension
+func decodeExternal(uri string, data []byte) (interface{}, error) {
+
+	if ext := strings.ToLower(path.Ext(uri)); ext == ".yaml" || ext == ".yml" {
+		var node yaml.Node
+
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, err
+		}
+
+		var b strings.Builder
+
+		if err := nodeToJSON(&b, &node); err != nil {
+			return nil, err
+		}
+
+		data = []byte(b.String())
+	}
+
+	var v interface{}
+
+	err := json.Unmarshal(data, &v)
+
+	return v, err
+}
+
+func copyObject(m map[string]interface{}) map[string]interface{} {
+
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+
+	return out
+}
+
+// rewriteSchema applies fn to the decoded json of v and returns the result as a Schema, or nil if fn changed nothing;
+// a nil fn converts v as it is
+func rewriteSchema(v interface{}, fn func(v interface{}) (interface{}, bool, error)) (Schema, error) {
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+
+	if fn != nil {
+		rewritten, changed, err := fn(decoded)
+		if err != nil || !changed {
+			return nil, err
+		}
+
+		if data, err = json.Marshal(rewritten); err != nil {
+			return nil, err
+		}
+	}
+
+	sch := NewSchema()
+
+	if err := sch.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+
+	return sch, nil
+}
+
+// rewriteRegistry applies fn to every schema of reg, replacing the schemas fn changes
+func rewriteRegistry(reg *SchemaRegistry, fn func(v interface{}) (interface{}, bool, error)) error {
+
+	for _, ptr := range reg.Schemas() {
+		current, _ := reg.Schema(ptr)
+
+		sch, err := rewriteSchema(current, fn)
+		if err != nil {
+			return errors.New(ptr.String() + ": " + err.Error())
+		}
+
+		if sch != nil {
+			if err := reg.Replace(ptr, sch); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// contentDescriptors calls fn for the params and result of every method that are not references, and for the
+// content descriptors declared in components/contentDescriptors
+func (doc *DocumentSpec1) contentDescriptors(fn func(cd *ContentDescriptor) error) error {
+
+	for _, m := range doc.Methods {
+		if m == nil {
+			continue
+		}
+
+		cds := append([]*ContentDescriptor{}, m.Params...)
+		if m.Result != nil {
+			cds = append(cds, m.Result)
+		}
+
+		for _, cd := range cds {
+			if cd == nil || cd.Ref != nil {
+				continue
+			}
+
+			if err := fn(cd); err != nil {
+				return errors.New("method " + m.Name + ": " + err.Error())
+			}
+		}
+	}
+
+	if doc.Components == nil || doc.Components.ContentDescriptors == nil {
+		return nil
+	}
+
+	reg := doc.Components.ContentDescriptors
+
+	for _, name := range reg.Names() {
+		cd, _ := reg.ContentDescriptor(name)
+
+		if err := fn(cd); err != nil {
+			return errors.New("components/contentDescriptors/" + name + ": " + err.Error())
+		}
+
+		reg.init(contentDescriptorsSection).set(name, *cd)
+	}
+
+	return nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,149 @@
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func loadBundleTestDocument(t *testing.T) *DocumentSpec1 {
+
+	data, err := ioutil.ReadFile("testdata/bundle/openrpc.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := ParseDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return doc
+}
+
+func TestParseRef(t *testing.T) {
+
+	for _, c := range []struct{ ref, doc, marshaled string }{
+		{"#/components/schemas/Foo", "", "#/components/schemas/Foo"},
+		{"./common.json#/Foo", "./common.json", "./common.json#/Foo"},
+		{"file:///specs/common.json", "file:///specs/common.json", "file:///specs/common.json#"},
+		{"common.yaml#/a~1b/c%5B%5D", "common.yaml", "common.yaml#/a~1b/c%5B%5D"},
+	} {
+		ptr, err := ParseRef(c.ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ext, isExternal := ptr.(ExternalPointer)
+		if isExternal != (c.doc != "") || isExternal && ext.Document() != c.doc {
+			t.Errorf("error, %s parsed as %#v", c.ref, ptr)
+		}
+
+		if data, _ := ptr.MarshalJSON(); string(data) != `{"$ref":"`+c.marshaled+`"}` {
+			t.Errorf("error, %s marshaled as %s", c.ref, data)
+		}
+	}
+}
+
+func TestBundle(t *testing.T) {
+
+	doc := loadBundleTestDocument(t)
+
+	bundled, err := Bundle(doc, FileLoader{Dir: "testdata/bundle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if data, _ := json.Marshal(bundled.Methods[0].Result.Schema); string(data) != `{"$ref":"#/components/schemas/Address"}` {
+		t.Errorf("error, unexpected result schema %s", data)
+	}
+
+	if data, _ := json.Marshal(bundled.Methods[1].Result); !strings.Contains(string(data), `"items":{"$ref":"#/components/schemas/city"}`) {
+		t.Errorf("error, unexpected result %s", data)
+	}
+
+	data, err := json.Marshal(bundled.Components.Schemas)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{` +
+		`"Id":{"type":"integer"},` +
+		`"Profile":{"properties":{"address":{"$ref":"#/components/schemas/Address"}},"type":"object"},` +
+		`"Address":{"properties":{"city":{"$ref":"#/components/schemas/city"},"id":{"$ref":"#/components/schemas/Id_2"},"street":{"type":"string"}},"type":"object"},` +
+		`"city":{"properties":{"country":{"$ref":"#/components/schemas/street"},"name":{"type":"string"}},"type":"object"},` +
+		`"street":{"type":"string"},` +
+		`"Id_2":{"pattern":"^[a-z]+$","type":"string"}}`
+
+	if string(data) != expected {
+		t.Errorf("error, unexpected components:\n%s\n%s", data, expected)
+	}
+
+	if err := bundled.Validate(); err != nil {
+		t.Errorf("error, bundled document is not valid: %v", err)
+	}
+
+	// the original document is left untouched
+	if _, ok := doc.Methods[0].Result.Schema.(ExternalPointer); !ok {
+		t.Error("error, Bundle modified its argument")
+	}
+}
+
+func TestDereference(t *testing.T) {
+
+	doc := loadBundleTestDocument(t)
+
+	inlined, err := Dereference(doc, FileLoader{Dir: "testdata/bundle"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(inlined.Methods[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(data), "$ref") {
+		t.Errorf("error, references left in %s", data)
+	}
+
+	expected := `"schema":{"properties":{"city":{"properties":{"country":{"type":"string"},"name":{"type":"string"}},"type":"object"},` +
+		`"id":{"pattern":"^[a-z]+$","type":"string"},"street":{"type":"string"}},"type":"object"}`
+
+	if !strings.Contains(string(data), expected) {
+		t.Errorf("error, unexpected method %s", data)
+	}
+
+	t.Run("circular", func(t *testing.T) {
+		doc, err := ParseDocument([]byte(`{
+			"openrpc": "1.2.6",
+			"info": {"title": "test", "version": "1"},
+			"methods": [{"name": "a", "params": [], "result": {"name": "r", "schema": {"$ref": "node.json"}}}]
+		}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		loader := LoaderFunc(func(uri string) ([]byte, error) {
+			if uri != "node.json" {
+				return nil, errors.New("unexpected uri " + uri)
+			}
+			return []byte(`{"type": "object", "properties": {"next": {"$ref": "#"}}}`), nil
+		})
+
+		if _, err := Dereference(doc, loader); err == nil || !strings.Contains(err.Error(), "circular reference node.json#") {
+			t.Errorf("error, got %v instead of a circular reference", err)
+		}
+
+		bundled, err := Bundle(doc, loader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if data, _ := json.Marshal(bundled.Components.Schemas); string(data) != `{"node":{"properties":{"next":{"$ref":"#/components/schemas/node"}},"type":"object"}}` {
+			t.Errorf("error, unexpected components %s", data)
+		}
+	})
+}
@@ -0,0 +1,273 @@
+package openrpc
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// CodedError is implemented by go errors that carry the code of the JSON-RPC error they stand for
+type CodedError interface {
+	error
+	ErrorCode() int
+}
+
+// DataError is implemented by go errors that carry the data of the JSON-RPC error they stand for
+type DataError interface {
+	error
+	ErrorData() interface{}
+}
+
+// ErrorCatalog declares the JSON-RPC errors of an API and maps go errors to them; it is safe for concurrent use
+type ErrorCatalog struct {
+	// ExposeMessages makes Translate send the message of go errors that translate to no declared error,
+	// instead of the generic message of InternalError; the message can leak internals of the implementation
+	ExposeMessages bool
+
+	mu      sync.RWMutex
+	schemas *SchemaRegistry
+	errors  map[int]Error
+	// dataSchemas are the schemas of the data of the errors carrying data, by code
+	dataSchemas map[int]Pointer
+	// matchers are tried in the order they were added, after CodedError
+	matchers []errorMatcher
+}
+
+type errorMatcher struct {
+	code  int
+	match func(err error) bool
+}
+
+// NewErrorCatalog returns an empty catalog registering the schemas of error data in schemas, which can be nil
+// if no error carries data
+func NewErrorCatalog(schemas *SchemaRegistry) *ErrorCatalog {
+	return &ErrorCatalog{schemas: schemas, errors: map[int]Error{}, dataSchemas: map[int]Pointer{}}
+}
+
+// Register declares the JSON-RPC error code; if data is not nil, it is the type of the data the error carries:
+// it is registered in the schemas of the catalog, see DataSchema
+func (c *ErrorCatalog) Register(code int, message string, data reflect.Type) error {
+
+	if message == "" {
+		return errors.New("error " + strconv.Itoa(code) + " has no message")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.errors[code]; ok {
+		return errors.New("error " + strconv.Itoa(code) + " is already declared")
+	}
+
+	if data != nil {
+		if c.schemas == nil {
+			return errors.New("error " + strconv.Itoa(code) + " carries data but the catalog has no schema registry")
+		}
+
+		ptr, _, err := c.schemas.RegisterType(data, false)
+		if err != nil {
+			return errors.New("error " + strconv.Itoa(code) + ": " + err.Error())
+		}
+
+		c.dataSchemas[code] = ptr
+	}
+
+	c.errors[code] = Error{Code: code, Message: message}
+
+	return nil
+}
+
+// Map translates the go errors matching target, as reported by errors.Is, to the declared error code
+func (c *ErrorCatalog) Map(target error, code int) error {
+	return c.addMatcher(code, func(err error) bool {
+		return errors.Is(err, target)
+	})
+}
+
+// MapType translates the go errors of the type of sample, as found by errors.As, to the declared error code
+func (c *ErrorCatalog) MapType(sample error, code int) error {
+
+	t := reflect.TypeOf(sample)
+	if t == nil {
+		return errors.New("nil sample error")
+	}
+
+	return c.addMatcher(code, func(err error) bool {
+		return errors.As(err, reflect.New(t).Interface())
+	})
+}
+
+func (c *ErrorCatalog) addMatcher(code int, match func(error) bool) error {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.errors[code]; !ok {
+		return errors.New("error " + strconv.Itoa(code) + " is not declared")
+	}
+
+	c.matchers = append(c.matchers, errorMatcher{code: code, match: match})
+
+	return nil
+}
+
+// Error returns the declared error code
+func (c *ErrorCatalog) Error(code int) (Error, bool) {
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	e, ok := c.errors[code]
+
+	return e, ok
+}
+
+// DataSchema returns the reference to the schema of the data the declared error code carries, if it carries data
+func (c *ErrorCatalog) DataSchema(code int) (Pointer, bool) {
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	ptr, ok := c.dataSchemas[code]
+
+	return ptr, ok
+}
+
+// Errors returns the declared errors, ordered by code
+func (c *ErrorCatalog) Errors() []Error {
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	errs := make([]Error, 0, len(c.errors))
+	for _, e := range c.errors {
+		errs = append(errs, e)
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Code < errs[j].Code })
+
+	return errs
+}
+
+// code returns the declared code err translates to
+func (c *ErrorCatalog) code(err error) (int, bool) {
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var coded CodedError
+	if errors.As(err, &coded) {
+		if _, ok := c.errors[coded.ErrorCode()]; ok {
+			return coded.ErrorCode(), true
+		}
+	}
+
+	for _, m := range c.matchers {
+		if m.match(err) {
+			return m.code, true
+		}
+	}
+
+	return 0, false
+}
+
+// Attach adds to the errors of m the declared errors that errs translate to, e.g. the sentinel errors and samples
+// of the error types its implementation returns. Errors m already lists are not added twice, comparing codes once
+// the references to the components/errors of doc are resolved; doc can be nil if m lists no references
+func (c *ErrorCatalog) Attach(doc *DocumentSpec1, m *Method, errs ...error) error {
+
+	registry := NewErrorRegistry()
+	if doc != nil {
+		registry = doc.sections().Errors
+	}
+
+	for _, err := range errs {
+		code, ok := c.code(err)
+		if !ok {
+			return errors.New("method " + m.Name + ": error " + strconv.Quote(err.Error()) + " is not declared")
+		}
+
+		e, _ := c.Error(code)
+
+		if !listsError(registry, m, e) {
+			m.Errors = append(m.Errors, e)
+		}
+	}
+
+	return nil
+}
+
+// Translate returns the JSON-RPC error a go error stands for: the declared error it translates to, with the data
+// of the go error if it implements DataError, or InternalError if it translates to no declared error, with the
+// message of the go error only if ExposeMessages is set. *Error values are returned as they are
+func (c *ErrorCatalog) Translate(err error) *Error {
+
+	if err == nil {
+		return nil
+	}
+
+	if e, ok := c.translate(err); ok {
+		return e
+	}
+
+	e := InternalError()
+	if c.ExposeMessages {
+		e.Message = err.Error()
+	}
+
+	return &e
+}
+
+// Fields returns the fields of the JSON-RPC error err translates to, and false if it translates to no declared
+// error. It serves as the MapError hook of the dispatchers gen emits, whose Error types have no Ref and so do not
+// convert from *Error; errors it does not translate are left to the dispatcher:
+//
+//	d.MapError = func(err error) *api.Error {
+//		if code, message, data, ok := catalog.Fields(err); ok {
+//			return &api.Error{Code: code, Message: message, Data: data}
+//		}
+//		return nil
+//	}
+func (c *ErrorCatalog) Fields(err error) (code int, message string, data interface{}, ok bool) {
+
+	if err == nil {
+		return 0, "", nil, false
+	}
+
+	e, ok := c.translate(err)
+	if !ok {
+		return 0, "", nil, false
+	}
+
+	return e.Code, e.Message, e.Data, true
+}
+
+// translate returns *Error values as they are, and the declared error other errors translate to
+func (c *ErrorCatalog) translate(err error) (*Error, bool) {
+
+	var rpcErr *Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr, true
+	}
+
+	code, ok := c.code(err)
+	if !ok {
+		return nil, false
+	}
+
+	declared, _ := c.Error(code)
+	e := &Error{Code: code, Message: declared.Message}
+
+	var dataErr DataError
+	if errors.As(err, &dataErr) {
+		e.Data = dataErr.ErrorData()
+	}
+
+	return e, true
+}
@@ -0,0 +1,191 @@
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+var errAccountNotFound = errors.New("account not found")
+
+type quotaData struct {
+	Limit int `json:"limit"`
+}
+
+type quotaError struct {
+	limit int
+}
+
+func (e *quotaError) Error() string {
+	return fmt.Sprintf("quota of %d calls exceeded", e.limit)
+}
+
+func (e *quotaError) ErrorData() interface{} {
+	return quotaData{Limit: e.limit}
+}
+
+type codedError int
+
+func (e codedError) Error() string {
+	return "coded error " + fmt.Sprint(int(e))
+}
+
+func (e codedError) ErrorCode() int {
+	return int(e)
+}
+
+func TestErrorCatalog(t *testing.T) {
+
+	ptr, _ := NewPointer("/components/schemas")
+
+	schemas, err := NewSchemaRegistry(ptr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewErrorCatalog(schemas)
+
+	for _, e := range []struct {
+		code    int
+		message string
+		data    reflect.Type
+	}{
+		{1001, "Account not found", nil},
+		{1002, "Quota exceeded", reflect.TypeOf(quotaData{})},
+		{1003, "Account locked", nil},
+	} {
+		if err := c.Register(e.code, e.message, e.data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := c.Register(1001, "Duplicate", nil); err == nil {
+		t.Error("error, registering a code twice should fail")
+	}
+
+	if err := c.Map(errAccountNotFound, 1001); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.MapType(&quotaError{}, 1002); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Map(errAccountNotFound, 2000); err == nil {
+		t.Error("error, mapping to an undeclared code should fail")
+	}
+
+	t.Run("translate", func(t *testing.T) {
+
+		for _, tc := range []struct {
+			err      error
+			expected string
+		}{
+			{nil, `null`},
+			{fmt.Errorf("loading: %w", errAccountNotFound), `{"code":1001,"message":"Account not found"}`},
+			{fmt.Errorf("calling: %w", &quotaError{limit: 10}), `{"code":1002,"message":"Quota exceeded","data":{"limit":10}}`},
+			{codedError(1003), `{"code":1003,"message":"Account locked"}`},
+			{codedError(1004), `{"code":-32603,"message":"Internal error"}`},
+			{errors.New("boom"), `{"code":-32603,"message":"Internal error"}`},
+			{&Error{Code: 7, Message: "as is"}, `{"code":7,"message":"as is"}`},
+		} {
+			data, err := json.Marshal(c.Translate(tc.err))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(data) != tc.expected {
+				t.Errorf("error, translating %v: expected %s, got %s", tc.err, tc.expected, data)
+			}
+		}
+
+		exposing := NewErrorCatalog(nil)
+		exposing.ExposeMessages = true
+
+		if e := exposing.Translate(errors.New("boom")); e.Code != -32603 || e.Message != "boom" {
+			t.Errorf("error, expected the message of the go error, got %v %s", e.Code, e.Message)
+		}
+	})
+
+	t.Run("attach", func(t *testing.T) {
+
+		m := &Method{Name: "withdraw", Errors: []Error{{Code: 1003, Message: "Account locked"}}}
+
+		if err := c.Attach(nil, m, errAccountNotFound, &quotaError{}, codedError(1003)); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := json.Marshal(m.Errors)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `[{"code":1003,"message":"Account locked"},{"code":1001,"message":"Account not found"},` +
+			`{"code":1002,"message":"Quota exceeded"}]`
+
+		if string(data) != expected {
+			t.Errorf("error, unexpected errors %s", data)
+		}
+
+		if err := c.Attach(nil, m, errors.New("unknown")); err == nil {
+			t.Error("error, attaching an undeclared error should fail")
+		}
+
+		ptr, ok := c.DataSchema(1002)
+		if !ok || ptr.String() != "/components/schemas/g0penrpc.quotaData" {
+			t.Errorf("error, unexpected data schema %s", ptr.String())
+		}
+
+		if _, ok := schemas.Schema(ptr); !ok {
+			t.Error("error, the data schema is not registered")
+		}
+
+		if _, ok := c.DataSchema(1001); ok {
+			t.Error("error, 1001 carries no data")
+		}
+	})
+
+	t.Run("attachReferences", func(t *testing.T) {
+
+		doc := &DocumentSpec1{Methods: []*Method{{Name: "withdraw"}}}
+
+		if err := doc.RegisterErrors(Error{Code: 1001, Message: "Account not found"}); err != nil {
+			t.Fatal(err)
+		}
+
+		m := doc.Methods[0]
+
+		if err := c.Attach(doc, m, errAccountNotFound, codedError(1003)); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := json.Marshal(m.Errors)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `[{"$ref":"#/components/errors/AccountNotFound"},{"code":1003,"message":"Account locked"}]`
+
+		if string(data) != expected {
+			t.Errorf("error, expected %s, got %s", expected, data)
+		}
+	})
+
+	t.Run("fields", func(t *testing.T) {
+
+		code, message, data, ok := c.Fields(fmt.Errorf("calling: %w", &quotaError{limit: 10}))
+		if !ok || code != 1002 || message != "Quota exceeded" || data != (quotaData{Limit: 10}) {
+			t.Errorf("error, unexpected fields %v %s %v %v", code, message, data, ok)
+		}
+
+		if _, _, _, ok := c.Fields(errors.New("boom")); ok {
+			t.Error("error, an undeclared error should be left to the dispatcher")
+		}
+
+		if _, _, _, ok := c.Fields(nil); ok {
+			t.Error("error, nil should translate to no error")
+		}
+	})
+}
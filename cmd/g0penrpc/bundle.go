@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "bundle",
+		usage:   "[-deref] [-o file] <document>",
+		summary: "move the schemas of external references into components/schemas, or inline every reference",
+		run:     runBundle,
+	})
+}
+
+func runBundle(cmd *command, args []string, stdout, stderr io.Writer) error {
+
+	fs := cmd.flags(stderr)
+	deref := fs.Bool("deref", false, "inline every reference instead of bundling external ones")
+	out := fs.String("o", "", "output file, stdout if empty; written as yaml if it has a .yaml or .yml extension")
+
+	if err := cmd.parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	doc, err := loadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	process := openrpc.Bundle
+	if *deref {
+		process = openrpc.Dereference
+	}
+
+	if doc, err = process(doc, openrpc.FileLoader{Dir: filepath.Dir(fs.Arg(0))}); err != nil {
+		return err
+	}
+
+	var data []byte
+
+	if ext := strings.ToLower(filepath.Ext(*out)); ext == ".yaml" || ext == ".yml" {
+		data, err = openrpc.MarshalDocumentYAML(doc)
+	} else if data, err = json.MarshalIndent(doc, "", "  "); err == nil {
+		data = append(data, '\n')
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(stdout, *out, data)
+}
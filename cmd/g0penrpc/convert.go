@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "convert",
+		usage:   "yaml|json [-o file] <document>",
+		summary: "re-encode a document as yaml or json",
+		run:     runConvert,
+	})
+}
+
+func runConvert(cmd *command, args []string, stdout, stderr io.Writer) error {
+
+	if len(args) == 0 {
+		cmd.flags(stderr).Usage()
+		return exitCode(2)
+	}
+
+	format := args[0]
+
+	fs := cmd.flags(stderr)
+	out := fs.String("o", "", "output file, stdout if empty")
+
+	if err := cmd.parse(fs, args[1:], 1); err != nil {
+		return err
+	}
+
+	doc, err := loadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+
+	switch format {
+	case "json":
+		if data, err = json.MarshalIndent(doc, "", "  "); err == nil {
+			data = append(data, '\n')
+		}
+	case "yaml":
+		data, err = openrpc.MarshalDocumentYAML(doc)
+	default:
+		return errors.New("unknown format " + format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(stdout, *out, data)
+}
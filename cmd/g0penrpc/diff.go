@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "diff",
+		usage:   "[-format text|json] <old> <new>",
+		summary: "list the changes between two documents, exiting with status 3 if any is breaking",
+		run:     runDiff,
+	})
+}
+
+func runDiff(cmd *command, args []string, stdout, stderr io.Writer) error {
+
+	fs := cmd.flags(stderr)
+	format := fs.String("format", "text", "output format, text or json")
+
+	if err := cmd.parse(fs, args, 2); err != nil {
+		return err
+	}
+
+	old, err := loadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	new, err := loadDocument(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	report, err := openrpc.Diff(old, new)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "text":
+		fmt.Fprint(stdout, report)
+	case "json":
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	default:
+		return errors.New("unknown format " + *format)
+	}
+
+	if report.Breaking() {
+		return exitCode(3)
+	}
+
+	return nil
+}
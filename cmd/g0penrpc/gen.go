@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"github.com/octanolabs/g0penrpc/gen"
+	"github.com/octanolabs/g0penrpc/render"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "gen",
+		usage:   "go|ts|md|html [-o file] [-package name] [-service name] [-template file] <document>",
+		summary: "generate Go server stubs, TypeScript types or documentation from a document",
+		run:     runGen,
+	})
+}
+
+func runGen(cmd *command, args []string, stdout, stderr io.Writer) error {
+
+	if len(args) == 0 {
+		cmd.flags(stderr).Usage()
+		return exitCode(2)
+	}
+
+	target := args[0]
+
+	fs := cmd.flags(stderr)
+	out := fs.String("o", "", "output file, stdout if empty")
+	pkg := fs.String("package", "api", "package of the generated Go code")
+	service := fs.String("service", "Service", "name of the generated Go interface")
+	tmpl := fs.String("template", "", "template overriding the default md or html one, or some of its named templates")
+
+	if err := cmd.parse(fs, args[1:], 1); err != nil {
+		return err
+	}
+
+	doc, err := loadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var opts render.Options
+
+	if *tmpl != "" {
+		data, err := ioutil.ReadFile(*tmpl)
+		if err != nil {
+			return err
+		}
+
+		opts.Template = string(data)
+	}
+
+	var src []byte
+
+	switch target {
+	case "go":
+		src, err = gen.Go(doc, gen.GoOptions{Package: *pkg, Service: *service})
+	case "ts":
+		src, err = gen.TypeScript(doc)
+	case "md":
+		src, err = render.Markdown(doc, opts)
+	case "html":
+		src, err = render.HTML(doc, opts)
+	default:
+		return errors.New("unknown target " + target)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(stdout, *out, src)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"github.com/octanolabs/g0penrpc/lint"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "lint",
+		usage:   "[-config file] [-format text|json|github] [-strict] <document>",
+		summary: "check the style of a document, exiting with status 1 on errors, or on warnings too with -strict",
+		run:     runLint,
+	})
+}
+
+func runLint(cmd *command, args []string, stdout, stderr io.Writer) error {
+
+	fs := cmd.flags(stderr)
+	config := fs.String("config", "", "json or yaml file enabling, disabling and setting the severity of rules")
+	format := fs.String("format", "text", "output format, text, json or github for GitHub Actions annotations")
+	strict := fs.Bool("strict", false, "fail on warnings too")
+
+	if err := cmd.parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	doc, err := loadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	l, err := lint.New(lint.DefaultRules()...)
+	if err != nil {
+		return err
+	}
+
+	if *config != "" {
+		data, err := ioutil.ReadFile(*config)
+		if err != nil {
+			return err
+		}
+
+		c, err := lint.ParseConfig(data)
+		if err != nil {
+			return errors.New(*config + ": " + err.Error())
+		}
+
+		if err := l.Configure(*c); err != nil {
+			return errors.New(*config + ": " + err.Error())
+		}
+	}
+
+	problems, err := l.Lint(doc)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "text":
+		err = lint.WriteText(stdout, problems)
+	case "json":
+		err = lint.WriteJSON(stdout, problems)
+	case "github":
+		err = lint.WriteGitHub(stdout, fs.Arg(0), problems)
+	default:
+		return errors.New("unknown format " + *format)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if lint.Failed(problems, *strict) {
+		return exitCode(1)
+	}
+
+	return nil
+}
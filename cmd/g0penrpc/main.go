@@ -0,0 +1,139 @@
+// Command g0penrpc works with openrpc documents from the command line
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+// command is a g0penrpc subcommand
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(cmd *command, args []string, stdout, stderr io.Writer) error
+}
+
+var commands []*command
+
+// exitCode is returned by commands to exit with a status other than 1, without printing an error
+type exitCode int
+
+func (c exitCode) Error() string {
+	return fmt.Sprintf("exit status %d", int(c))
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the command named by the first of args and returns the exit status
+func run(args []string, stdout, stderr io.Writer) int {
+
+	if len(args) < 1 {
+		usage(stderr)
+		return 2
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+
+		err := cmd.run(cmd, args[1:], stdout, stderr)
+
+		var code exitCode
+		if errors.As(err, &code) {
+			return int(code)
+		}
+
+		if err != nil {
+			fmt.Fprintln(stderr, "g0penrpc "+cmd.name+": "+err.Error())
+			return 1
+		}
+
+		return 0
+	}
+
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: g0penrpc <command> [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+}
+
+// flags returns a flag set printing the usage of cmd to stderr
+func (cmd *command) flags(stderr io.Writer) *flag.FlagSet {
+
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: g0penrpc "+cmd.name+" "+cmd.usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parse parses the arguments of cmd and checks that it got n positional arguments
+func (cmd *command) parse(fs *flag.FlagSet, args []string, n int) error {
+
+	if err := fs.Parse(args); err != nil {
+		return exitCode(2)
+	}
+
+	if fs.NArg() != n {
+		fs.Usage()
+		return exitCode(2)
+	}
+
+	return nil
+}
+
+// loadDocument reads a json document, or a yaml one if path has a .yaml or .yml extension
+func loadDocument(path string) (*openrpc.DocumentSpec1, error) {
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	parse := openrpc.ParseDocument
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		parse = openrpc.ParseDocumentYAML
+	}
+
+	doc, err := parse(data)
+	if err != nil {
+		return nil, errors.New(path + ": " + err.Error())
+	}
+
+	return doc, nil
+}
+
+// writeOutput writes data to path, or to stdout if path is empty
+func writeOutput(stdout io.Writer, path string, data []byte) error {
+
+	if path == "" {
+		_, err := stdout.Write(data)
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
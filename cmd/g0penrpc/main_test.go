@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/octanolabs/g0penrpc/internal/testutil"
+)
+
+// writeDocuments writes the documents the commands are run on that are not in the testdata directory of the module:
+// the petstore without its ping method, and a document failing validation
+func writeDocuments(t *testing.T, dir string) {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(testutil.Path("petstore.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	methods := doc["methods"].([]interface{})
+	doc["methods"] = methods[:len(methods)-1]
+
+	if data, err = json.Marshal(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "noping.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := `{"openrpc": "1.2.6", "info": {"title": "invalid"}, "methods": []}`
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "invalid.json"), []byte(invalid), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "g0penrpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeDocuments(t, dir)
+
+	petstore := testutil.Path("petstore.json")
+	noping := filepath.Join(dir, "noping.json")
+	invalid := filepath.Join(dir, "invalid.json")
+	missing := filepath.Join(dir, "missing.json")
+
+	cases := []struct {
+		name string
+		args []string
+		code int
+		// stdout and stderr are expected within the output of the command
+		stdout, stderr string
+	}{
+		{"noCommand", nil, 2, "", "usage: g0penrpc <command> [arguments]"},
+		{"unknownCommand", []string{"format"}, 2, "", "usage: g0penrpc <command> [arguments]"},
+
+		{"validate", []string{"validate", petstore}, 0, "", ""},
+		{"validateInvalid", []string{"validate", invalid}, 1, "info/version: is required", ""},
+		{"validateMissing", []string{"validate", missing}, 1, "", "g0penrpc validate: open " + missing},
+		{"validateUsage", []string{"validate"}, 2, "", "usage: g0penrpc validate <document>"},
+
+		{"convertYAML", []string{"convert", "yaml", petstore}, 0, "openrpc: 1.2.6", ""},
+		{"convertJSON", []string{"convert", "json", petstore}, 0, `"title": "Petstore"`, ""},
+		{"convertUnknown", []string{"convert", "xml", petstore}, 1, "", "g0penrpc convert: unknown format xml"},
+
+		{"diffSame", []string{"diff", petstore, petstore}, 0, "", ""},
+		{"diffBreaking", []string{"diff", petstore, noping}, 3, "ping", ""},
+		{"diffJSON", []string{"diff", "-format", "json", petstore, noping}, 3, `"breaking": true`, ""},
+		{"diffUsage", []string{"diff", petstore}, 2, "", "usage: g0penrpc diff"},
+
+		{"lint", []string{"lint", petstore}, 0, "method ping has no summary (method-summary)", ""},
+		{"lintStrict", []string{"lint", "-strict", petstore}, 1, "(declared-tags)", ""},
+		{"lintUnknownFormat", []string{"lint", "-format", "xml", petstore}, 1, "", "g0penrpc lint: unknown format xml"},
+
+		{"bundle", []string{"bundle", petstore}, 0, `"openrpc": "1.2.6"`, ""},
+		{"bundleDerefRecursive", []string{"bundle", "-deref", petstore}, 1, "", "circular reference #/components/schemas/Family"},
+
+		{"genGo", []string{"gen", "go", "-package", "petstore", petstore}, 0, "package petstore", ""},
+		{"genTypeScript", []string{"gen", "ts", petstore}, 0, "export type PetId = number;", ""},
+		{"genMarkdown", []string{"gen", "md", petstore}, 0, "# Petstore", ""},
+		{"genHTML", []string{"gen", "html", petstore}, 0, "<title>Petstore 1.0.0</title>", ""},
+		{"genUnknown", []string{"gen", "rust", petstore}, 1, "", "g0penrpc gen: unknown target rust"},
+
+		{"serveMissing", []string{"serve", missing}, 1, "", "g0penrpc serve: open " + missing},
+		{"serveAddress", []string{"serve", "-addr", "localhost:-1", petstore}, 1, "", "g0penrpc serve: listen tcp"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+
+			var stdout, stderr bytes.Buffer
+
+			if code := run(c.args, &stdout, &stderr); code != c.code {
+				t.Errorf("error, expected exit status %d, got %d:\n%s%s", c.code, code, stdout.String(), stderr.String())
+			}
+
+			if !strings.Contains(stdout.String(), c.stdout) {
+				t.Errorf("error, expected %q on stdout, got:\n%s", c.stdout, stdout.String())
+			}
+
+			if !strings.Contains(stderr.String(), c.stderr) {
+				t.Errorf("error, expected %q on stderr, got:\n%s", c.stderr, stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/octanolabs/g0penrpc/mock"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "serve",
+		usage:   "[-addr host:port] <document>",
+		summary: "serve a mock JSON-RPC endpoint answering with examples or results synthesized from schemas",
+		run:     runServe,
+	})
+}
+
+func runServe(cmd *command, args []string, stdout, stderr io.Writer) error {
+
+	fs := cmd.flags(stderr)
+	addr := fs.String("addr", "localhost:8545", "address to listen on")
+
+	if err := cmd.parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	doc, err := loadDocument(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	srv, err := mock.NewServer(doc)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stderr, "serving %s on %s, set the %s header to simulate an error\n", fs.Arg(0), *addr, mock.ErrorHeader)
+
+	return http.ListenAndServe(*addr, srv)
+}
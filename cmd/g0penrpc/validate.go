@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+func init() {
+	commands = append(commands, &command{
+		name:    "validate",
+		usage:   "<document>",
+		summary: "check a document against the openrpc meta-schema, resolve its references and check its examples",
+		run:     runValidate,
+	})
+}
+
+func runValidate(cmd *command, args []string, stdout, stderr io.Writer) error {
+
+	fs := cmd.flags(stderr)
+
+	if err := cmd.parse(fs, args, 1); err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	validate := openrpc.ValidateDocument
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		validate = openrpc.ValidateDocumentYAML
+	}
+
+	if err := validate(data); err != nil {
+		if errs, ok := err.(openrpc.ValidationErrors); ok {
+			for _, e := range errs {
+				fmt.Fprintln(stdout, e)
+			}
+			return exitCode(1)
+		}
+		return errors.New(path + ": " + err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	jsch "github.com/qri-io/jsonschema"
+)
+
+// ErrExternalRef signals a schema that cannot be compiled without loading other documents
+var ErrExternalRef = errors.New("external reference")
+
+// Compile compiles a decoded schema of the document into a validator, see CompileSchema
+func (r *RefResolver) Compile(sch interface{}) (*jsch.Schema, error) {
+	return CompileSchema(sch, r.Resolve)
+}
+
+// CompileSchema compiles a decoded schema into a validator, resolving its local references with resolve; since the
+// validator only resolves references within a schema, the targets of local references are copied under $defs and the
+// references rewritten, which also compiles recursive schemas. It returns ErrExternalRef for schemas referring to
+// other documents
+func CompileSchema(sch interface{}, resolve func(ref string) (interface{}, error)) (*jsch.Schema, error) {
+
+	defs := map[string]interface{}{}
+	names := map[string]string{}
+
+	var rewrite func(node interface{}) (interface{}, error)
+
+	rewrite = func(node interface{}) (interface{}, error) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			out := make(map[string]interface{}, len(n))
+
+			for k, child := range n {
+				if ref, ok := child.(string); ok && k == "$ref" {
+					if !strings.HasPrefix(ref, "#") {
+						return nil, ErrExternalRef
+					}
+
+					name, ok := names[ref]
+					if !ok {
+						name = "ref" + strconv.Itoa(len(names))
+						names[ref] = name
+
+						target, err := resolve(ref)
+						if err != nil {
+							return nil, err
+						}
+
+						// the name is taken before rewriting the target, so that recursive schemas terminate
+						if defs[name], err = rewrite(target); err != nil {
+							return nil, err
+						}
+					}
+
+					out[k] = "#/$defs/" + name
+					continue
+				}
+
+				rewritten, err := rewrite(child)
+				if err != nil {
+					return nil, err
+				}
+				out[k] = rewritten
+			}
+
+			return out, nil
+		case []interface{}:
+			out := make([]interface{}, len(n))
+
+			for i, child := range n {
+				rewritten, err := rewrite(child)
+				if err != nil {
+					return nil, err
+				}
+				out[i] = rewritten
+			}
+
+			return out, nil
+		}
+
+		return node, nil
+	}
+
+	root, err := rewrite(sch)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"$defs": defs, "allOf": []interface{}{root}})
+	if err != nil {
+		return nil, err
+	}
+
+	rs := &jsch.Schema{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
@@ -0,0 +1,793 @@
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// componentSection is a section of the components object and the go type of its values
+type componentSection struct {
+	name string
+	typ  reflect.Type
+}
+
+// The sections of the components object held by componentStores
+var (
+	contentDescriptorsSection = componentSection{"contentDescriptors", reflect.TypeOf(ContentDescriptor{})}
+	errorsSection             = componentSection{"errors", reflect.TypeOf(Error{})}
+	examplesSection           = componentSection{"examples", reflect.TypeOf(Example{})}
+	linksSection              = componentSection{"links", reflect.TypeOf(Link{})}
+	examplePairingsSection    = componentSection{"examplePairingObjects", reflect.TypeOf(ExamplePairing{})}
+	tagsSection               = componentSection{"tags", reflect.TypeOf(Tag{})}
+)
+
+// componentStore holds the named values of a section of the components object, in the order they are declared;
+// the typed registries of the sections wrap it. It is safe for concurrent use
+type componentStore struct {
+	mu      sync.RWMutex
+	section componentSection
+	names   []string
+	values  map[string]interface{}
+}
+
+func newComponentStore(section componentSection) *componentStore {
+	return &componentStore{section: section, values: map[string]interface{}{}}
+}
+
+// pointer returns the pointer of the value declared as name, e.g. /components/tags/name
+func (s *componentStore) pointer(name string) Pointer {
+	return newPointerFromRefs([]string{"components", s.section.name, name})
+}
+
+// name returns the name of the value a pointer into the section points to
+func (s *componentStore) name(ptr Pointer) (string, bool) {
+
+	if ptr == nil {
+		return "", false
+	}
+
+	if _, ok := ptr.(ExternalPointer); ok {
+		return "", false
+	}
+
+	refs := ptr.Refs()
+	if len(refs) != 3 || refs[0] != "components" || refs[1] != s.section.name {
+		return "", false
+	}
+
+	return refs[2], true
+}
+
+func (s *componentStore) get(name string) (interface{}, bool) {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	v, ok := s.values[name]
+
+	return v, ok
+}
+
+// declare sets the value of name, failing if a value with a different json encoding is already declared as name
+func (s *componentStore) declare(name string, v interface{}) (Pointer, error) {
+
+	if ref, ok := refOf(v); ok {
+		return nil, errors.New("cannot declare the reference " + ref.String() + " in components/" + s.section.name)
+	}
+
+	if name == "" {
+		return nil, errors.New("components/" + s.section.name + " values must have a name")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ptr := s.pointer(name)
+
+	if existing, ok := s.values[name]; ok {
+		a, err := json.Marshal(existing)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if !bytes.Equal(a, b) {
+			return nil, errors.New("a different value is already declared as " + ptr.String())
+		}
+
+		return ptr, nil
+	}
+
+	s.names = append(s.names, name)
+	s.values[name] = v
+
+	return ptr, nil
+}
+
+// set sets the value of name, keeping its position if it is already declared
+func (s *componentStore) set(name string, v interface{}) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[name]; !ok {
+		s.names = append(s.names, name)
+	}
+	s.values[name] = v
+}
+
+func (s *componentStore) remove(name string) bool {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[name]; !ok {
+		return false
+	}
+
+	delete(s.values, name)
+
+	for i, n := range s.names {
+		if n == name {
+			s.names = append(s.names[:i:i], s.names[i+1:]...)
+			break
+		}
+	}
+
+	return true
+}
+
+// list returns the names declared in the section, in the order they were declared
+func (s *componentStore) list() []string {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]string{}, s.names...)
+}
+
+func (s *componentStore) marshalJSON() ([]byte, error) {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for i, name := range s.names {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key, err := json.Marshal(name)
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := json.Marshal(s.values[name])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// unmarshalJSON decodes a json object of named values of the type of the section, keeping the order of its keys
+func (s *componentStore) unmarshalJSON(data []byte) error {
+
+	names, named, err := decodeObject(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.values == nil {
+		s.values = map[string]interface{}{}
+	}
+
+	for _, name := range names {
+		v, err := s.decode(named[name])
+		if err != nil {
+			return errors.New("error decoding " + name + ": " + err.Error())
+		}
+
+		if _, ok := s.values[name]; !ok {
+			s.names = append(s.names, name)
+		}
+		s.values[name] = v
+	}
+
+	return nil
+}
+
+// resolve returns the value a reference points to, failing if it is not declared in the section
+func (s *componentStore) resolve(ref Pointer) (interface{}, error) {
+
+	name, ok := s.name(ref)
+	if !ok {
+		return nil, errors.New("reference " + ref.String() + " does not point into components/" + s.section.name)
+	}
+
+	v, ok := s.get(name)
+	if !ok {
+		return nil, errors.New(name + " is not declared in components/" + s.section.name)
+	}
+
+	return v, nil
+}
+
+// refOf returns the reference of the values of the components sections that are references
+func refOf(v interface{}) (Pointer, bool) {
+
+	var ref Pointer
+
+	switch c := v.(type) {
+	case ContentDescriptor:
+		ref = c.Ref
+	case Error:
+		ref = c.Ref
+	case Example:
+		ref = c.Ref
+	case Link:
+		ref = c.Ref
+	case ExamplePairing:
+		ref = c.Ref
+	case Tag:
+		ref = c.Ref
+	}
+
+	return ref, ref != nil
+}
+
+// decode decodes a value of the section, rejecting references
+func (s *componentStore) decode(raw json.RawMessage) (interface{}, error) {
+
+	ptr := reflect.New(s.section.typ)
+
+	if err := json.Unmarshal(raw, ptr.Interface()); err != nil {
+		return nil, err
+	}
+
+	v := ptr.Elem().Interface()
+
+	if ref, ok := refOf(v); ok {
+		return nil, errors.New("the reference " + ref.String() + " cannot be declared as a component")
+	}
+
+	return v, nil
+}
+
+// componentRegistry is embedded by the registries of the components sections: it provides the methods that do not
+// depend on the type of the values, the registries add the accessors converting them. Its zero value is empty:
+// the store of the section is created by the first accessor of the registry that knows the section
+type componentRegistry struct {
+	mu    sync.Mutex
+	store *componentStore
+}
+
+// init returns the store of the registry, creating an empty one of section if there is none yet
+func (r *componentRegistry) init(section componentSection) *componentStore {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.store == nil {
+		r.store = newComponentStore(section)
+	}
+
+	return r.store
+}
+
+// loaded returns the store of the registry, or nil if it has none yet
+func (r *componentRegistry) loaded() *componentStore {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.store
+}
+
+// Names returns the names of the declared values, in the order they were declared
+func (r *componentRegistry) Names() []string {
+
+	s := r.loaded()
+	if s == nil {
+		return []string{}
+	}
+
+	return s.list()
+}
+
+// Remove drops the value declared as name and reports whether there was one; references to it are left untouched
+func (r *componentRegistry) Remove(name string) bool {
+
+	s := r.loaded()
+	if s == nil {
+		return false
+	}
+
+	return s.remove(name)
+}
+
+// ref returns the pointer of the value declared as name, if any
+func (r *componentRegistry) ref(name string) (Pointer, bool) {
+
+	s := r.loaded()
+	if s == nil {
+		return nil, false
+	}
+
+	if _, ok := s.get(name); !ok {
+		return nil, false
+	}
+
+	return s.pointer(name), true
+}
+
+func (r *componentRegistry) MarshalJSON() ([]byte, error) {
+
+	s := r.loaded()
+	if s == nil {
+		return []byte("{}"), nil
+	}
+
+	return s.marshalJSON()
+}
+
+// unmarshalJSON decodes the values of section, for the UnmarshalJSON methods of the registries
+func (r *componentRegistry) unmarshalJSON(data []byte, section componentSection) error {
+	return r.init(section).unmarshalJSON(data)
+}
+
+// ContentDescriptorRegistry holds the content descriptors declared in components/contentDescriptors, by name,
+// so that params and results shared by several methods are declared once; it is safe for concurrent use
+type ContentDescriptorRegistry struct {
+	componentRegistry
+}
+
+// NewContentDescriptorRegistry returns an empty registry of the components/contentDescriptors section
+func NewContentDescriptorRegistry() *ContentDescriptorRegistry {
+	return &ContentDescriptorRegistry{componentRegistry{store: newComponentStore(contentDescriptorsSection)}}
+}
+
+// Register declares cd as name and returns the pointer by which methods reference it;
+// it fails if a different content descriptor is already declared as name
+func (r *ContentDescriptorRegistry) Register(name string, cd ContentDescriptor) (Pointer, error) {
+	return r.init(contentDescriptorsSection).declare(name, cd)
+}
+
+// ContentDescriptor returns a copy of the content descriptor declared as name
+func (r *ContentDescriptorRegistry) ContentDescriptor(name string) (*ContentDescriptor, bool) {
+
+	v, ok := r.init(contentDescriptorsSection).get(name)
+	if !ok {
+		return nil, false
+	}
+
+	cd := v.(ContentDescriptor)
+
+	return &cd, true
+}
+
+// Ref returns a reference to the content descriptor declared as name, to be used as a param or result of methods
+func (r *ContentDescriptorRegistry) Ref(name string) (*ContentDescriptor, bool) {
+
+	ptr, ok := r.ref(name)
+	if !ok {
+		return nil, false
+	}
+
+	return &ContentDescriptor{Ref: ptr}, true
+}
+
+// Resolve returns a copy of the content descriptor a reference points to, or cd itself if it is not a reference
+func (r *ContentDescriptorRegistry) Resolve(cd *ContentDescriptor) (*ContentDescriptor, error) {
+
+	if cd == nil || cd.Ref == nil {
+		return cd, nil
+	}
+
+	v, err := r.init(contentDescriptorsSection).resolve(cd.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := v.(ContentDescriptor)
+
+	return &resolved, nil
+}
+
+func (r *ContentDescriptorRegistry) UnmarshalJSON(data []byte) error {
+	return r.unmarshalJSON(data, contentDescriptorsSection)
+}
+
+// ErrorRegistry holds the errors declared in components/errors, by name; it is safe for concurrent use
+type ErrorRegistry struct {
+	componentRegistry
+}
+
+// NewErrorRegistry returns an empty registry of the components/errors section
+func NewErrorRegistry() *ErrorRegistry {
+	return &ErrorRegistry{componentRegistry{store: newComponentStore(errorsSection)}}
+}
+
+// Register declares e as name and returns the pointer by which methods reference it;
+// it fails if a different error is already declared as name
+func (r *ErrorRegistry) Register(name string, e Error) (Pointer, error) {
+	return r.init(errorsSection).declare(name, e)
+}
+
+// Error returns the error declared as name
+func (r *ErrorRegistry) Error(name string) (Error, bool) {
+
+	v, ok := r.init(errorsSection).get(name)
+	if !ok {
+		return Error{}, false
+	}
+
+	return v.(Error), true
+}
+
+// Ref returns a reference to the error declared as name, to be listed in the errors of methods
+func (r *ErrorRegistry) Ref(name string) (Error, bool) {
+
+	ptr, ok := r.ref(name)
+
+	return Error{Ref: ptr}, ok
+}
+
+// Resolve returns the error a reference points to, or e itself if it is not a reference
+func (r *ErrorRegistry) Resolve(e Error) (Error, error) {
+
+	if e.Ref == nil {
+		return e, nil
+	}
+
+	v, err := r.init(errorsSection).resolve(e.Ref)
+	if err != nil {
+		return Error{}, err
+	}
+
+	return v.(Error), nil
+}
+
+func (r *ErrorRegistry) UnmarshalJSON(data []byte) error {
+	return r.unmarshalJSON(data, errorsSection)
+}
+
+// ExampleRegistry holds the examples declared in components/examples, by name; it is safe for concurrent use
+type ExampleRegistry struct {
+	componentRegistry
+}
+
+// NewExampleRegistry returns an empty registry of the components/examples section
+func NewExampleRegistry() *ExampleRegistry {
+	return &ExampleRegistry{componentRegistry{store: newComponentStore(examplesSection)}}
+}
+
+// Register declares ex as name and returns the pointer by which example pairings reference it;
+// it fails if a different example is already declared as name
+func (r *ExampleRegistry) Register(name string, ex Example) (Pointer, error) {
+	return r.init(examplesSection).declare(name, ex)
+}
+
+// Example returns a copy of the example declared as name
+func (r *ExampleRegistry) Example(name string) (*Example, bool) {
+
+	v, ok := r.init(examplesSection).get(name)
+	if !ok {
+		return nil, false
+	}
+
+	ex := v.(Example)
+
+	return &ex, true
+}
+
+// Ref returns a reference to the example declared as name, to be used as a param or result of example pairings
+func (r *ExampleRegistry) Ref(name string) (*Example, bool) {
+
+	ptr, ok := r.ref(name)
+	if !ok {
+		return nil, false
+	}
+
+	return &Example{Ref: ptr}, true
+}
+
+// Resolve returns a copy of the example a reference points to, or ex itself if it is not a reference
+func (r *ExampleRegistry) Resolve(ex *Example) (*Example, error) {
+
+	if ex == nil || ex.Ref == nil {
+		return ex, nil
+	}
+
+	v, err := r.init(examplesSection).resolve(ex.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := v.(Example)
+
+	return &resolved, nil
+}
+
+func (r *ExampleRegistry) UnmarshalJSON(data []byte) error {
+	return r.unmarshalJSON(data, examplesSection)
+}
+
+// LinkRegistry holds the links declared in components/links, by name; it is safe for concurrent use
+type LinkRegistry struct {
+	componentRegistry
+}
+
+// NewLinkRegistry returns an empty registry of the components/links section
+func NewLinkRegistry() *LinkRegistry {
+	return &LinkRegistry{componentRegistry{store: newComponentStore(linksSection)}}
+}
+
+// Register declares l as name and returns the pointer by which methods reference it;
+// it fails if a different link is already declared as name
+func (r *LinkRegistry) Register(name string, l Link) (Pointer, error) {
+	return r.init(linksSection).declare(name, l)
+}
+
+// Link returns the link declared as name
+func (r *LinkRegistry) Link(name string) (Link, bool) {
+
+	v, ok := r.init(linksSection).get(name)
+	if !ok {
+		return Link{}, false
+	}
+
+	return v.(Link), true
+}
+
+// Ref returns a reference to the link declared as name, to be listed in the links of methods
+func (r *LinkRegistry) Ref(name string) (Link, bool) {
+
+	ptr, ok := r.ref(name)
+
+	return Link{Ref: ptr}, ok
+}
+
+// Resolve returns the link a reference points to, or l itself if it is not a reference
+func (r *LinkRegistry) Resolve(l Link) (Link, error) {
+
+	if l.Ref == nil {
+		return l, nil
+	}
+
+	v, err := r.init(linksSection).resolve(l.Ref)
+	if err != nil {
+		return Link{}, err
+	}
+
+	return v.(Link), nil
+}
+
+func (r *LinkRegistry) UnmarshalJSON(data []byte) error {
+	return r.unmarshalJSON(data, linksSection)
+}
+
+// ExamplePairingRegistry holds the example pairings declared in components/examplePairingObjects, by name;
+// it is safe for concurrent use
+type ExamplePairingRegistry struct {
+	componentRegistry
+}
+
+// NewExamplePairingRegistry returns an empty registry of the components/examplePairingObjects section
+func NewExamplePairingRegistry() *ExamplePairingRegistry {
+	return &ExamplePairingRegistry{componentRegistry{store: newComponentStore(examplePairingsSection)}}
+}
+
+// Register declares p as name and returns the pointer by which methods reference it;
+// it fails if a different pairing is already declared as name
+func (r *ExamplePairingRegistry) Register(name string, p ExamplePairing) (Pointer, error) {
+	return r.init(examplePairingsSection).declare(name, p)
+}
+
+// ExamplePairing returns a copy of the pairing declared as name
+func (r *ExamplePairingRegistry) ExamplePairing(name string) (*ExamplePairing, bool) {
+
+	v, ok := r.init(examplePairingsSection).get(name)
+	if !ok {
+		return nil, false
+	}
+
+	p := v.(ExamplePairing)
+
+	return &p, true
+}
+
+// Ref returns a reference to the pairing declared as name, to be listed in the examples of methods
+func (r *ExamplePairingRegistry) Ref(name string) (*ExamplePairing, bool) {
+
+	ptr, ok := r.ref(name)
+	if !ok {
+		return nil, false
+	}
+
+	return &ExamplePairing{Ref: ptr}, true
+}
+
+// Resolve returns a copy of the pairing a reference points to, or p itself if it is not a reference
+func (r *ExamplePairingRegistry) Resolve(p *ExamplePairing) (*ExamplePairing, error) {
+
+	if p == nil || p.Ref == nil {
+		return p, nil
+	}
+
+	v, err := r.init(examplePairingsSection).resolve(p.Ref)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := v.(ExamplePairing)
+
+	return &resolved, nil
+}
+
+func (r *ExamplePairingRegistry) UnmarshalJSON(data []byte) error {
+	return r.unmarshalJSON(data, examplePairingsSection)
+}
+
+// sections returns the components of doc, with an empty registry in place of every missing section
+func (doc *DocumentSpec1) sections() *Components {
+
+	c := &Components{}
+	if doc.Components != nil {
+		*c = *doc.Components
+	}
+
+	if c.ContentDescriptors == nil {
+		c.ContentDescriptors = NewContentDescriptorRegistry()
+	}
+	if c.Examples == nil {
+		c.Examples = NewExampleRegistry()
+	}
+	if c.Links == nil {
+		c.Links = NewLinkRegistry()
+	}
+	if c.Errors == nil {
+		c.Errors = NewErrorRegistry()
+	}
+	if c.ExamplePairingObjects == nil {
+		c.ExamplePairingObjects = NewExamplePairingRegistry()
+	}
+	if c.Tags == nil {
+		c.Tags = NewTagRegistry()
+	}
+
+	return c
+}
+
+// ResolveMethod returns a copy of m in which the references to the components of doc, in its params, result, errors,
+// links, examples and tags, are replaced with the values they point to; references to schemas are left as they are
+func (doc *DocumentSpec1) ResolveMethod(m *Method) (*Method, error) {
+
+	c := doc.sections()
+	out := *m
+
+	if m.Params != nil {
+		out.Params = make([]*ContentDescriptor, len(m.Params))
+
+		for i, p := range m.Params {
+			cd, err := c.ContentDescriptors.Resolve(p)
+			if err != nil {
+				return nil, fmt.Errorf("params/%d: %v", i, err)
+			}
+			out.Params[i] = cd
+		}
+	}
+
+	result, err := c.ContentDescriptors.Resolve(m.Result)
+	if err != nil {
+		return nil, fmt.Errorf("result: %v", err)
+	}
+	out.Result = result
+
+	if m.Errors != nil {
+		out.Errors = make([]Error, len(m.Errors))
+
+		for i, e := range m.Errors {
+			if out.Errors[i], err = c.Errors.Resolve(e); err != nil {
+				return nil, fmt.Errorf("errors/%d: %v", i, err)
+			}
+		}
+	}
+
+	if m.Links != nil {
+		out.Links = make([]Link, len(m.Links))
+
+		for i, l := range m.Links {
+			if out.Links[i], err = c.Links.Resolve(l); err != nil {
+				return nil, fmt.Errorf("links/%d: %v", i, err)
+			}
+		}
+	}
+
+	if m.Examples != nil {
+		out.Examples = make([]*ExamplePairing, len(m.Examples))
+
+		for i, ex := range m.Examples {
+			if out.Examples[i], err = c.examplePairing(ex); err != nil {
+				return nil, fmt.Errorf("examples/%d: %v", i, err)
+			}
+		}
+	}
+
+	if m.Tags != nil {
+		if out.Tags, err = doc.Tags(m); err != nil {
+			return nil, err
+		}
+	}
+
+	return &out, nil
+}
+
+// examplePairing resolves a pairing and the examples of its params and result
+func (c *Components) examplePairing(ex *ExamplePairing) (*ExamplePairing, error) {
+
+	ex, err := c.ExamplePairingObjects.Resolve(ex)
+	if err != nil || ex == nil {
+		return ex, err
+	}
+
+	out := *ex
+
+	if ex.Params != nil {
+		out.Params = make([]*Example, len(ex.Params))
+
+		for i, p := range ex.Params {
+			if out.Params[i], err = c.Examples.Resolve(p); err != nil {
+				return nil, fmt.Errorf("params/%d: %v", i, err)
+			}
+		}
+	}
+
+	if out.Result, err = c.Examples.Resolve(ex.Result); err != nil {
+		return nil, fmt.Errorf("result: %v", err)
+	}
+
+	return &out, nil
+}
+
+// ResolveMethods returns the methods of doc resolved with ResolveMethod, at the same indexes; null methods stay nil
+func (doc *DocumentSpec1) ResolveMethods() ([]*Method, error) {
+
+	methods := make([]*Method, len(doc.Methods))
+
+	for i, m := range doc.Methods {
+		if m == nil {
+			continue
+		}
+
+		resolved, err := doc.ResolveMethod(m)
+		if err != nil {
+			return nil, errors.New("method " + m.Name + ": " + err.Error())
+		}
+
+		methods[i] = resolved
+	}
+
+	return methods, nil
+}
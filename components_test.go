@@ -0,0 +1,261 @@
+package openrpc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const componentsDocument = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "chain", "version": "1"},
+	"methods": [
+		{
+			"name": "getBlock",
+			"params": [{"$ref": "#/components/contentDescriptors/blockNumber"}],
+			"result": {"name": "block", "schema": {"$ref": "#/components/schemas/block"}},
+			"errors": [{"$ref": "#/components/errors/notFound"}],
+			"links": [{"$ref": "#/components/links/balance"}],
+			"examples": [{"$ref": "#/components/examplePairingObjects/genesis"}]
+		},
+		{
+			"name": "getBalance",
+			"params": [
+				{"name": "address", "required": true, "schema": {"type": "string"}},
+				{"$ref": "#/components/contentDescriptors/blockNumber"}
+			],
+			"result": {"name": "balance", "schema": {"type": "integer"}},
+			"examples": [
+				{"name": "latest", "params": [{"name": "address", "value": "0x0"}, {"$ref": "#/components/examples/genesisNumber"}], "result": {"name": "balance", "value": 0}}
+			]
+		}
+	],
+	"components": {
+		"schemas": {
+			"block": {"type": "object", "properties": {"number": {"type": "integer"}}}
+		},
+		"contentDescriptors": {
+			"blockNumber": {"name": "blockNumber", "required": true, "schema": {"type": "integer", "minimum": 0}}
+		},
+		"errors": {
+			"notFound": {"code": 404, "message": "Not found"}
+		},
+		"links": {
+			"balance": {"name": "balance", "method": "getBalance"}
+		},
+		"examples": {
+			"genesisNumber": {"name": "blockNumber", "value": 0}
+		},
+		"examplePairingObjects": {
+			"genesis": {"name": "genesis", "params": [{"$ref": "#/components/examples/genesisNumber"}], "result": {"name": "block", "value": {"number": 0}}}
+		}
+	}
+}`
+
+func TestComponentRegistries(t *testing.T) {
+
+	doc, err := ParseDocument([]byte(componentsDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("error, unexpected validation errors %v", err)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`"params":[{"$ref":"#/components/contentDescriptors/blockNumber"}]`,
+		`"errors":[{"$ref":"#/components/errors/notFound"}]`,
+		`"links":[{"$ref":"#/components/links/balance"}]`,
+		`"examples":[{"$ref":"#/components/examplePairingObjects/genesis"}]`,
+		`"contentDescriptors":{"blockNumber":{"name":"blockNumber","required":true,"schema":{"type":"integer","minimum":0}}}`,
+		`"errors":{"notFound":{"code":404,"message":"Not found"}}`,
+		`"examplePairingObjects":{"genesis":{"name":"genesis","params":[{"$ref":"#/components/examples/genesisNumber"}]`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("error, expected %s in %s", expected, data)
+		}
+	}
+
+	c := doc.Components
+
+	ptr, err := c.ContentDescriptors.Register("address", ContentDescriptor{Name: "address", Schema: newPointerFromRefs([]string{"components", "schemas", "block"})})
+	if err != nil || ptr.String() != "/components/contentDescriptors/address" {
+		t.Errorf("error, unexpected pointer %v, %v", ptr, err)
+	}
+
+	if _, err := c.ContentDescriptors.Register("address", ContentDescriptor{Name: "other"}); err == nil {
+		t.Errorf("error, registering a different content descriptor under a taken name should fail")
+	}
+
+	if _, err := c.Errors.Register("ref", Error{Ref: ptr}); err == nil {
+		t.Errorf("error, registering a reference should fail")
+	}
+
+	if names := strings.Join(c.ContentDescriptors.Names(), ","); names != "blockNumber,address" {
+		t.Errorf("error, expected content descriptors in declaration order, got %s", names)
+	}
+
+	if ref, ok := c.Links.Ref("balance"); !ok || ref.Ref.String() != "/components/links/balance" {
+		t.Errorf("error, unexpected link reference %v", ref)
+	}
+
+	if _, ok := c.Examples.Ref("missing"); ok {
+		t.Errorf("error, expected no reference to an undeclared example")
+	}
+
+	if !c.ContentDescriptors.Remove("address") || c.ContentDescriptors.Remove("address") {
+		t.Errorf("error, expected address to be removed once")
+	}
+
+	t.Run("resolver", func(t *testing.T) {
+
+		res, err := NewRefResolver(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c.Errors.Remove("notFound")
+
+		e, err := res.Error(doc.Methods[0].Errors[0])
+		if err != nil || e.Code != 404 || e.Message != "Not found" {
+			t.Errorf("error, expected the error of the snapshot, got %v, %v", e, err)
+		}
+
+		if _, err := res.Error(Error{Ref: newPointerFromRefs([]string{"components", "links", "balance"})}); err == nil {
+			t.Errorf("error, resolving a reference outside components/errors should fail")
+		}
+	})
+}
+
+// TestZeroRegistries checks that registries declared without their constructors are usable
+func TestZeroRegistries(t *testing.T) {
+
+	errs := &ErrorRegistry{}
+
+	if names := errs.Names(); len(names) != 0 || errs.Remove("missing") {
+		t.Errorf("error, expected an empty registry, got %v", names)
+	}
+
+	if data, err := json.Marshal(errs); err != nil || string(data) != "{}" {
+		t.Errorf("error, unexpected encoding %s, %v", data, err)
+	}
+
+	ptr, err := errs.Register("notFound", Error{Code: 404, Message: "Not found"})
+	if err != nil || ptr.String() != "/components/errors/notFound" {
+		t.Errorf("error, unexpected pointer %v, %v", ptr, err)
+	}
+
+	if ref, ok := errs.Ref("notFound"); !ok || ref.Ref.String() != "/components/errors/notFound" {
+		t.Errorf("error, unexpected error reference %v", ref)
+	}
+
+	tags := &TagRegistry{}
+
+	if _, err := tags.Register(Tag{Name: "pets"}); err != nil || strings.Join(tags.Names(), ",") != "pets" {
+		t.Errorf("error, expected the tag to be declared, got %v, %v", tags.Names(), err)
+	}
+}
+
+func TestResolveMethod(t *testing.T) {
+
+	doc, err := ParseDocument([]byte(componentsDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := doc.ResolveMethod(doc.Methods[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Params[0].Name != "blockNumber" || !m.Params[0].Required || m.Params[0].InlineSchema == nil {
+		t.Errorf("error, unexpected param %+v", m.Params[0])
+	}
+
+	if m.Errors[0].Code != 404 || m.Links[0].Method != "getBalance" {
+		t.Errorf("error, unexpected errors %v or links %v", m.Errors, m.Links)
+	}
+
+	if ex := m.Examples[0]; ex.Name != "genesis" || ex.Params[0].Name != "blockNumber" || ex.Params[0].Value != float64(0) {
+		t.Errorf("error, unexpected example %+v", ex)
+	}
+
+	// the method of the document is left as it is
+	if doc.Methods[0].Params[0].Ref == nil {
+		t.Errorf("error, expected the method of the document to keep its references")
+	}
+
+	doc.Components.ContentDescriptors.Remove("blockNumber")
+
+	if _, err := doc.ResolveMethods(); err == nil || err.Error() != "method getBlock: params/0: blockNumber is not declared in components/contentDescriptors" {
+		t.Errorf("error, unexpected error %v", err)
+	}
+}
+
+func TestValidateComponentRefs(t *testing.T) {
+
+	cases := map[string]struct {
+		from, to string
+		expected string
+	}{
+		"undeclared": {
+			`{"$ref": "#/components/errors/notFound"}`, `{"$ref": "#/components/errors/missing"}`,
+			"methods/0/errors/0: unresolved reference #/components/errors/missing",
+		},
+		"wrong section": {
+			`"links": [{"$ref": "#/components/links/balance"}]`, `"links": [{"$ref": "#/components/errors/notFound"}]`,
+			"methods/0: links/0: reference /components/errors/notFound does not point into components/links",
+		},
+		"declared content descriptor": {
+			`{"name": "blockNumber", "required": true, "schema"`, `{"required": true, "schema"`,
+			"components/contentDescriptors/blockNumber/name: is required",
+		},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+
+			doc, err := ParseDocument([]byte(strings.Replace(componentsDocument, c.from, c.to, 1)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := doc.Validate(); err == nil || err.Error() != c.expected {
+				t.Errorf("error, expected %s, got %v", c.expected, err)
+			}
+		})
+	}
+}
+
+func TestDereferenceComponents(t *testing.T) {
+
+	doc, err := ParseDocument([]byte(componentsDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deref, err := Dereference(doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(deref.Methods[1].Params[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != `{"name":"blockNumber","required":true,"schema":{"minimum":0,"type":"integer"}}` {
+		t.Errorf("error, unexpected dereferenced param %s", data)
+	}
+
+	// the content descriptors of two methods are not shared
+	if deref.Methods[0].Params[0] == deref.Methods[1].Params[1] {
+		t.Errorf("error, expected every method to get its own copy of a referenced content descriptor")
+	}
+}
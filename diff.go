@@ -0,0 +1,552 @@
+package openrpc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/octanolabs/g0penrpc/internal/jsonutil"
+)
+
+// Change is a single difference between two versions of a document
+type Change struct {
+	Breaking bool   `json:"breaking"`
+	Path     string `json:"path"`
+	Message  string `json:"message"`
+}
+
+// DiffReport lists the changes between two versions of a document
+type DiffReport struct {
+	Changes []Change `json:"changes"`
+}
+
+// Breaking reports whether any of the changes is breaking
+func (r *DiffReport) Breaking() bool {
+	for _, c := range r.Changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the report with one change per line
+func (r *DiffReport) String() string {
+
+	var b strings.Builder
+
+	for _, c := range r.Changes {
+		kind := "non-breaking"
+		if c.Breaking {
+			kind = "BREAKING"
+		}
+		fmt.Fprintf(&b, "%-13s %s: %s\n", kind, c.Path, c.Message)
+	}
+
+	return b.String()
+}
+
+// Diff compares two versions of a document from the point of view of a client of the old version:
+// changes which can make existing calls fail, or existing response handling break, are reported as breaking
+func Diff(old, next *DocumentSpec1) (*DiffReport, error) {
+
+	oldRes, err := NewRefResolver(old)
+	if err != nil {
+		return nil, err
+	}
+
+	nextRes, err := NewRefResolver(next)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &differ{old: oldRes, next: nextRes, report: &DiffReport{Changes: []Change{}}}
+
+	// null methods are skipped, as by ResolveMethods
+	nextMethods := map[string]*Method{}
+	for _, m := range next.Methods {
+		if m != nil {
+			nextMethods[m.Name] = m
+		}
+	}
+
+	oldMethods := map[string]*Method{}
+	for _, m := range old.Methods {
+		if m == nil {
+			continue
+		}
+
+		oldMethods[m.Name] = m
+
+		path := "methods/" + m.Name
+
+		nm, ok := nextMethods[m.Name]
+		if !ok {
+			d.add(true, path, "method removed")
+			continue
+		}
+
+		rm, err := old.ResolveMethod(m)
+		if err != nil {
+			return nil, errors.New("old method " + m.Name + ": " + err.Error())
+		}
+
+		rnm, err := next.ResolveMethod(nm)
+		if err != nil {
+			return nil, errors.New("new method " + nm.Name + ": " + err.Error())
+		}
+
+		if err := d.method(path, rm, rnm); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, m := range next.Methods {
+		if m == nil {
+			continue
+		}
+
+		if _, ok := oldMethods[m.Name]; !ok {
+			d.add(false, "methods/"+m.Name, "method added")
+		}
+	}
+
+	return d.report, nil
+}
+
+// direction tells whether a schema describes values sent by clients (params) or received by them (results)
+type direction int
+
+const (
+	input direction = iota
+	output
+)
+
+type differ struct {
+	old, next *RefResolver
+	report    *DiffReport
+	// seen holds pairs of references already compared, to stop on recursive schemas
+	seen map[[2]string]bool
+}
+
+func (d *differ) add(breaking bool, path, format string, args ...interface{}) {
+	d.report.Changes = append(d.report.Changes, Change{Breaking: breaking, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// narrowed records a change which restricts the accepted values: breaking for params, safe for results
+func (d *differ) narrowed(dir direction, path, format string, args ...interface{}) {
+	d.add(dir == input, path, format, args...)
+}
+
+// widened records a change which allows more values: safe for params, breaking for results
+func (d *differ) widened(dir direction, path, format string, args ...interface{}) {
+	d.add(dir == output, path, format, args...)
+}
+
+func (d *differ) method(path string, old, next *Method) error {
+
+	if !old.Deprecated && next.Deprecated {
+		d.add(false, path, "method deprecated")
+	}
+
+	if old.ParamStructure != next.ParamStructure {
+		breaking := next.ParamStructure != "" && next.ParamStructure != "either"
+		d.add(breaking, path, "param structure changed from %q to %q", old.ParamStructure, next.ParamStructure)
+	}
+
+	nextParams := map[string]int{}
+	for i, p := range next.Params {
+		if p != nil {
+			nextParams[p.Name] = i
+		}
+	}
+
+	oldParams := map[string]bool{}
+	for i, p := range old.Params {
+		if p == nil {
+			continue
+		}
+
+		oldParams[p.Name] = true
+
+		ppath := path + "/params/" + p.Name
+
+		j, ok := nextParams[p.Name]
+		if !ok {
+			d.add(true, ppath, "param removed")
+			continue
+		}
+
+		np := next.Params[j]
+
+		if i != j && next.ParamStructure != "by-name" {
+			d.add(true, ppath, "param moved from position %d to %d", i, j)
+		}
+
+		if !p.Required && np.Required {
+			d.add(true, ppath, "param became required")
+		} else if p.Required && !np.Required {
+			d.add(false, ppath, "param became optional")
+		}
+
+		if err := d.contentDescriptor(ppath, p, np, input); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range next.Params {
+		if p != nil && !oldParams[p.Name] {
+			if p.Required {
+				d.add(true, path+"/params/"+p.Name, "required param added")
+			} else {
+				d.add(false, path+"/params/"+p.Name, "optional param added")
+			}
+		}
+	}
+
+	switch {
+	case old.Result == nil && next.Result == nil:
+	case old.Result == nil:
+		d.add(false, path+"/result", "result added")
+	case next.Result == nil:
+		// clients of the old version may rely on the result
+		d.add(true, path+"/result", "result removed")
+	default:
+		if err := d.contentDescriptor(path+"/result", old.Result, next.Result, output); err != nil {
+			return err
+		}
+	}
+
+	oldErrors, err := errorCodes(d.old, old.Errors)
+	if err != nil {
+		return err
+	}
+
+	nextErrors, err := errorCodes(d.next, next.Errors)
+	if err != nil {
+		return err
+	}
+
+	for _, code := range sortedCodes(nextErrors) {
+		if !oldErrors[code] {
+			d.add(false, path+"/errors", "error %d added", code)
+		}
+	}
+
+	for _, code := range sortedCodes(oldErrors) {
+		if !nextErrors[code] {
+			d.add(false, path+"/errors", "error %d removed", code)
+		}
+	}
+
+	return nil
+}
+
+func (d *differ) contentDescriptor(path string, old, next *ContentDescriptor, dir direction) error {
+
+	oldSch, err := d.old.Schema(old)
+	if err != nil {
+		return err
+	}
+
+	nextSch, err := d.next.Schema(next)
+	if err != nil {
+		return err
+	}
+
+	d.seen = map[[2]string]bool{}
+
+	return d.schema(path+"/schema", oldSch, nextSch, dir)
+}
+
+func (d *differ) schema(path string, old, next interface{}, dir direction) error {
+
+	oldRef, _ := RefOf(old)
+	nextRef, _ := RefOf(next)
+
+	if oldRef != "" || nextRef != "" {
+		if d.seen[[2]string{oldRef, nextRef}] {
+			return nil
+		}
+		d.seen[[2]string{oldRef, nextRef}] = true
+	}
+
+	old, err := d.old.Deref(old)
+	if err != nil {
+		return err
+	}
+
+	next, err = d.next.Deref(next)
+	if err != nil {
+		return err
+	}
+
+	o, _ := old.(map[string]interface{})
+	n, _ := next.(map[string]interface{})
+
+	d.types(path, typeSet(o), typeSet(n), dir)
+	d.enum(path, o["enum"], n["enum"], dir)
+	d.bounds(path, o, n, dir)
+
+	if op, np := o["pattern"], n["pattern"]; op != np {
+		switch {
+		case op == nil:
+			d.narrowed(dir, path, "pattern %v added", np)
+		case np == nil:
+			d.widened(dir, path, "pattern %v removed", op)
+		default:
+			d.add(true, path, "pattern changed from %v to %v", op, np)
+		}
+	}
+
+	if err := d.properties(path, o, n, dir); err != nil {
+		return err
+	}
+
+	if oi, ni := o["items"], n["items"]; oi != nil && ni != nil {
+		return d.schema(path+"/items", oi, ni, dir)
+	}
+
+	return nil
+}
+
+func (d *differ) types(path string, old, next map[string]bool, dir direction) {
+
+	// a missing type keyword allows any type
+	if len(old) == 0 && len(next) == 0 {
+		return
+	}
+
+	if len(old) == 0 {
+		d.narrowed(dir, path, "type restricted to %s", setString(next))
+		return
+	}
+
+	if len(next) == 0 {
+		d.widened(dir, path, "type restriction %s removed", setString(old))
+		return
+	}
+
+	overlap := false
+	for t := range old {
+		overlap = overlap || next[t] || (t == "integer" && next["number"]) || (t == "number" && next["integer"])
+	}
+
+	if !overlap {
+		d.add(true, path, "type changed from %s to %s", setString(old), setString(next))
+		return
+	}
+
+	for _, t := range sortedSet(old) {
+		if !next[t] && !(t == "integer" && next["number"]) {
+			d.narrowed(dir, path, "type %s removed", t)
+		}
+	}
+
+	for _, t := range sortedSet(next) {
+		if !old[t] && !(t == "integer" && old["number"]) {
+			d.widened(dir, path, "type %s added", t)
+		}
+	}
+}
+
+func (d *differ) enum(path string, old, next interface{}, dir direction) {
+
+	oldValues, oldOk := old.([]interface{})
+	nextValues, nextOk := next.([]interface{})
+
+	switch {
+	case !oldOk && !nextOk:
+		return
+	case !oldOk:
+		d.narrowed(dir, path, "enum added")
+		return
+	case !nextOk:
+		d.widened(dir, path, "enum removed")
+		return
+	}
+
+	for _, v := range oldValues {
+		if !containsValue(nextValues, v) {
+			d.narrowed(dir, path, "enum value %s removed", jsonutil.String(v))
+		}
+	}
+
+	for _, v := range nextValues {
+		if !containsValue(oldValues, v) {
+			d.widened(dir, path, "enum value %s added", jsonutil.String(v))
+		}
+	}
+}
+
+// lower bounds restrict values when they grow, upper bounds when they shrink
+var (
+	lowerBounds = []string{"minimum", "exclusiveMinimum", "minLength", "minItems", "minProperties"}
+	upperBounds = []string{"maximum", "exclusiveMaximum", "maxLength", "maxItems", "maxProperties"}
+)
+
+func (d *differ) bounds(path string, old, next map[string]interface{}, dir direction) {
+
+	compare := func(key string, upper bool) {
+		ov, oldOk := old[key].(float64)
+		nv, nextOk := next[key].(float64)
+
+		switch {
+		case !oldOk && !nextOk, oldOk && nextOk && ov == nv:
+		case !oldOk:
+			d.narrowed(dir, path, "%s %v added", key, nv)
+		case !nextOk:
+			d.widened(dir, path, "%s %v removed", key, ov)
+		case (nv > ov) != upper:
+			d.narrowed(dir, path, "%s changed from %v to %v", key, ov, nv)
+		default:
+			d.widened(dir, path, "%s changed from %v to %v", key, ov, nv)
+		}
+	}
+
+	for _, key := range lowerBounds {
+		compare(key, false)
+	}
+
+	for _, key := range upperBounds {
+		compare(key, true)
+	}
+}
+
+func (d *differ) properties(path string, old, next map[string]interface{}, dir direction) error {
+
+	oldProps, _ := old["properties"].(map[string]interface{})
+	nextProps, _ := next["properties"].(map[string]interface{})
+
+	oldRequired, nextRequired := stringSet(old["required"]), stringSet(next["required"])
+
+	names := map[string]bool{}
+	for name := range oldProps {
+		names[name] = true
+	}
+	for name := range nextProps {
+		names[name] = true
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		ppath := path + "/properties/" + name
+		op, inOld := oldProps[name]
+		np, inNew := nextProps[name]
+
+		switch {
+		case !inNew:
+			// clients may rely on a result field, while a removed param field is at most ignored
+			d.add(dir == output, ppath, "property removed")
+		case !inOld:
+			if nextRequired[name] {
+				d.narrowed(dir, ppath, "required property added")
+			} else {
+				d.add(false, ppath, "optional property added")
+			}
+		default:
+			if !oldRequired[name] && nextRequired[name] {
+				d.narrowed(dir, ppath, "property became required")
+			} else if oldRequired[name] && !nextRequired[name] {
+				d.widened(dir, ppath, "property became optional")
+			}
+
+			if err := d.schema(ppath, op, np, dir); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func typeSet(sch map[string]interface{}) map[string]bool {
+
+	set := map[string]bool{}
+
+	switch t := sch["type"].(type) {
+	case string:
+		set[t] = true
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				set[s] = true
+			}
+		}
+	}
+
+	return set
+}
+
+func stringSet(v interface{}) map[string]bool {
+
+	set := map[string]bool{}
+
+	list, _ := v.([]interface{})
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			set[s] = true
+		}
+	}
+
+	return set
+}
+
+func sortedSet(set map[string]bool) []string {
+
+	items := make([]string, 0, len(set))
+	for k := range set {
+		items = append(items, k)
+	}
+	sort.Strings(items)
+
+	return items
+}
+
+func setString(set map[string]bool) string {
+	return strings.Join(sortedSet(set), "|")
+}
+
+func containsValue(values []interface{}, v interface{}) bool {
+	for _, item := range values {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorCodes returns the codes of errors, resolving references
+func errorCodes(res *RefResolver, errs []Error) (map[int]bool, error) {
+
+	codes := map[int]bool{}
+
+	for _, e := range errs {
+		e, err := res.Error(e)
+		if err != nil {
+			return nil, err
+		}
+
+		codes[e.Code] = true
+	}
+
+	return codes, nil
+}
+
+func sortedCodes(codes map[int]bool) []int {
+
+	sorted := make([]int, 0, len(codes))
+	for code := range codes {
+		sorted = append(sorted, code)
+	}
+	sort.Ints(sorted)
+
+	return sorted
+}
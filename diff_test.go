@@ -0,0 +1,176 @@
+package openrpc
+
+import (
+	"testing"
+)
+
+const diffOldDocument = `{
+	"openrpc": "1.2.0",
+	"info": {"title": "test", "version": "1.0.0"},
+	"methods": [
+		{
+			"name": "getBlock",
+			"params": [
+				{"name": "number", "required": true, "schema": {"type": "integer", "minimum": 0}},
+				{"name": "full", "schema": {"type": "boolean"}}
+			],
+			"result": {"name": "block", "schema": {"$ref": "#/components/schemas/Block"}}
+		},
+		{
+			"name": "getStatus",
+			"params": [],
+			"result": {"name": "status", "schema": {"type": "string", "enum": ["syncing", "synced"]}}
+		},
+		{
+			"name": "removed",
+			"params": [],
+			"result": {"name": "nothing", "schema": {}}
+		}
+	],
+	"components": {
+		"schemas": {
+			"Block": {
+				"type": "object",
+				"required": ["hash"],
+				"properties": {
+					"hash": {"type": "string"},
+					"number": {"type": "integer"}
+				}
+			}
+		}
+	}
+}`
+
+const diffNewDocument = `{
+	"openrpc": "1.2.0",
+	"info": {"title": "test", "version": "2.0.0"},
+	"methods": [
+		{
+			"name": "getBlock",
+			"params": [
+				{"name": "number", "required": true, "schema": {"type": "integer", "minimum": 1}},
+				{"name": "full", "schema": {"type": "boolean"}},
+				{"name": "txs", "required": true, "schema": {"type": "boolean"}}
+			],
+			"result": {"name": "block", "schema": {"$ref": "#/components/schemas/Block"}}
+		},
+		{
+			"name": "getStatus",
+			"params": [],
+			"result": {"name": "status", "schema": {"type": "string", "enum": ["syncing"]}}
+		},
+		{
+			"name": "added",
+			"params": [],
+			"result": {"name": "nothing", "schema": {}}
+		}
+	],
+	"components": {
+		"schemas": {
+			"Block": {
+				"type": "object",
+				"required": ["hash"],
+				"properties": {
+					"hash": {"type": "string"},
+					"number": {"type": "string"},
+					"parent": {"type": "string"}
+				}
+			}
+		}
+	}
+}`
+
+func TestDiff(t *testing.T) {
+
+	old, err := ParseDocument([]byte(diffOldDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := ParseDocument([]byte(diffNewDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Diff(old, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Breaking: true, Path: "methods/getBlock/params/number/schema", Message: "minimum changed from 0 to 1"},
+		{Breaking: true, Path: "methods/getBlock/params/txs", Message: "required param added"},
+		{Breaking: true, Path: "methods/getBlock/result/schema/properties/number", Message: "type changed from integer to string"},
+		{Breaking: false, Path: "methods/getBlock/result/schema/properties/parent", Message: "optional property added"},
+		{Breaking: false, Path: "methods/getStatus/result/schema", Message: "enum value \"synced\" removed"},
+		{Breaking: true, Path: "methods/removed", Message: "method removed"},
+		{Breaking: false, Path: "methods/added", Message: "method added"},
+	}
+
+	if len(report.Changes) != len(want) {
+		t.Fatalf("error, got %v changes instead of %v:\n%v", len(report.Changes), len(want), report)
+	}
+
+	for i, c := range report.Changes {
+		if c != want[i] {
+			t.Errorf("error, got %+v instead of %+v", c, want[i])
+		}
+	}
+
+	if !report.Breaking() {
+		t.Errorf("error, report should be breaking")
+	}
+
+	t.Run("identical", func(t *testing.T) {
+
+		report, err := Diff(old, old)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(report.Changes) != 0 {
+			t.Errorf("error, got changes comparing a document with itself:\n%v", report)
+		}
+	})
+
+	t.Run("results", func(t *testing.T) {
+
+		old, err := ParseDocument([]byte(`{"openrpc": "1.2.0", "info": {"title": "test", "version": "1"}, "methods": [
+			null,
+			{"name": "getBlock", "params": [], "result": {"name": "block", "schema": {"type": "object"}}},
+			{"name": "ping", "params": []}
+		]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		next, err := ParseDocument([]byte(`{"openrpc": "1.2.0", "info": {"title": "test", "version": "2"}, "methods": [
+			{"name": "getBlock", "params": []},
+			null,
+			{"name": "ping", "params": [], "result": {"name": "pong", "schema": {"type": "string"}}}
+		]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		report, err := Diff(old, next)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []Change{
+			{Breaking: true, Path: "methods/getBlock/result", Message: "result removed"},
+			{Breaking: false, Path: "methods/ping/result", Message: "result added"},
+		}
+
+		if len(report.Changes) != len(want) {
+			t.Fatalf("error, got %v changes instead of %v:\n%v", len(report.Changes), len(want), report)
+		}
+
+		for i, c := range report.Changes {
+			if c != want[i] {
+				t.Errorf("error, got %+v instead of %+v", c, want[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,405 @@
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+// ParseDocument decodes a json encoded openrpc document
+func ParseDocument(data []byte) (*DocumentSpec1, error) {
+
+	doc := &DocumentSpec1{}
+
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// MarshalJSON leaves out the contact of info when it is empty, which omitempty does not do for structs
+func (info Info) MarshalJSON() ([]byte, error) {
+
+	var contact *Contact
+	if info.Contact != (Contact{}) {
+		contact = &info.Contact
+	}
+
+	return json.Marshal(struct {
+		Title          string   `json:"title"`
+		Description    string   `json:"description,omitempty"`
+		TermsOfService string   `json:"termsOfService,omitempty"`
+		Contact        *Contact `json:"contact,omitempty"`
+		License        *License `json:"license,omitempty"`
+		Version        string   `json:"version"`
+	}{info.Title, info.Description, info.TermsOfService, contact, info.License, info.Version})
+}
+
+// MarshalJSON writes a content descriptor as a reference, or its schema either as a reference or inline
+func (cd ContentDescriptor) MarshalJSON() ([]byte, error) {
+	type alias ContentDescriptor
+
+	if cd.Ref != nil {
+		return cd.Ref.MarshalJSON()
+	}
+
+	var sch interface{} = cd.Schema
+
+	if cd.Schema == nil && cd.InlineSchema != nil {
+		sch = cd.InlineSchema
+	}
+
+	return json.Marshal(struct {
+		*alias
+		Schema interface{} `json:"schema"`
+	}{alias: (*alias)(&cd), Schema: sch})
+}
+
+// MarshalJSON writes an error either as a reference or inline
+func (e Error) MarshalJSON() ([]byte, error) {
+	type alias Error
+
+	if e.Ref != nil {
+		return e.Ref.MarshalJSON()
+	}
+
+	return json.Marshal(alias(e))
+}
+
+// UnmarshalJSON reads a reference object into Ref, or an inline error into the other fields
+func (e *Error) UnmarshalJSON(data []byte) error {
+	type alias Error
+
+	ptr, err := decodeRef(data)
+	if err != nil {
+		return errors.New("error decoding error reference: " + err.Error())
+	}
+
+	if ptr != nil {
+		*e = Error{Ref: ptr}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*alias)(e))
+}
+
+// MarshalJSON writes a tag either as a reference or inline
+func (t Tag) MarshalJSON() ([]byte, error) {
+	type alias Tag
+
+	if t.Ref != nil {
+		return t.Ref.MarshalJSON()
+	}
+
+	return json.Marshal(alias(t))
+}
+
+// UnmarshalJSON reads a reference object into Ref, or an inline tag into the other fields
+func (t *Tag) UnmarshalJSON(data []byte) error {
+	type alias Tag
+
+	ptr, err := decodeRef(data)
+	if err != nil {
+		return errors.New("error decoding tag reference: " + err.Error())
+	}
+
+	if ptr != nil {
+		*t = Tag{Ref: ptr}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*alias)(t))
+}
+
+// MarshalJSON writes a link either as a reference or inline
+func (l Link) MarshalJSON() ([]byte, error) {
+	type alias Link
+
+	if l.Ref != nil {
+		return l.Ref.MarshalJSON()
+	}
+
+	return json.Marshal(alias(l))
+}
+
+// UnmarshalJSON reads a reference object into Ref, or an inline link into the other fields
+func (l *Link) UnmarshalJSON(data []byte) error {
+	type alias Link
+
+	ptr, err := decodeRef(data)
+	if err != nil {
+		return errors.New("error decoding link reference: " + err.Error())
+	}
+
+	if ptr != nil {
+		*l = Link{Ref: ptr}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*alias)(l))
+}
+
+// MarshalJSON writes an example either as a reference or inline
+func (ex Example) MarshalJSON() ([]byte, error) {
+	type alias Example
+
+	if ex.Ref != nil {
+		return ex.Ref.MarshalJSON()
+	}
+
+	return json.Marshal(alias(ex))
+}
+
+// UnmarshalJSON reads a reference object into Ref, or an inline example into the other fields
+func (ex *Example) UnmarshalJSON(data []byte) error {
+	type alias Example
+
+	ptr, err := decodeRef(data)
+	if err != nil {
+		return errors.New("error decoding example reference: " + err.Error())
+	}
+
+	if ptr != nil {
+		*ex = Example{Ref: ptr}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*alias)(ex))
+}
+
+// MarshalJSON writes an example pairing either as a reference or inline
+func (p ExamplePairing) MarshalJSON() ([]byte, error) {
+	type alias ExamplePairing
+
+	if p.Ref != nil {
+		return p.Ref.MarshalJSON()
+	}
+
+	return json.Marshal(alias(p))
+}
+
+// UnmarshalJSON reads a reference object into Ref, or an inline example pairing into the other fields
+func (p *ExamplePairing) UnmarshalJSON(data []byte) error {
+	type alias ExamplePairing
+
+	ptr, err := decodeRef(data)
+	if err != nil {
+		return errors.New("error decoding example pairing reference: " + err.Error())
+	}
+
+	if ptr != nil {
+		*p = ExamplePairing{Ref: ptr}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*alias)(p))
+}
+
+// decodeRef returns the pointer of a reference object, or nil if data is not one
+func decodeRef(data []byte) (Pointer, error) {
+
+	var ref struct {
+		Ref *string `json:"$ref"`
+	}
+
+	if err := json.Unmarshal(data, &ref); err != nil {
+		return nil, err
+	}
+
+	if ref.Ref == nil {
+		return nil, nil
+	}
+
+	return ParseRef(*ref.Ref)
+}
+
+// UnmarshalJSON reads a reference object into Ref; otherwise the schema of the content descriptor is read
+// into Schema when it is a reference, or into InlineSchema
+func (cd *ContentDescriptor) UnmarshalJSON(data []byte) error {
+	type alias ContentDescriptor
+
+	ptr, err := decodeRef(data)
+	if err != nil {
+		return errors.New("error decoding content descriptor reference: " + err.Error())
+	}
+
+	if ptr != nil {
+		*cd = ContentDescriptor{Ref: ptr}
+		return nil
+	}
+
+	aux := struct {
+		*alias
+		Schema json.RawMessage `json:"schema"`
+	}{alias: (*alias)(cd)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	ptr, sch, err := decodeSchemaOrRef(aux.Schema)
+	if err != nil {
+		return errors.New("error decoding schema of content descriptor " + cd.Name + ": " + err.Error())
+	}
+
+	cd.Schema, cd.InlineSchema = ptr, sch
+
+	return nil
+}
+
+// decodeSchemaOrRef returns a Pointer if data is a reference object, and a Schema otherwise
+func decodeSchemaOrRef(data json.RawMessage) (Pointer, Schema, error) {
+
+	if len(data) == 0 {
+		return nil, nil, nil
+	}
+
+	var obj map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &obj); err == nil && len(obj) == 1 {
+		if raw, ok := obj["$ref"]; ok {
+			var ref string
+
+			if err := json.Unmarshal(raw, &ref); err != nil {
+				return nil, nil, err
+			}
+
+			ptr, err := ParseRef(ref)
+
+			return ptr, nil, err
+		}
+	}
+
+	sch := NewSchema()
+
+	if err := sch.UnmarshalJSON(data); err != nil {
+		return nil, nil, err
+	}
+
+	return nil, sch, nil
+}
+
+// UnmarshalJSON decodes a json object of named schemas into the registry;
+// schemas are stored under the unmarshalFrom pointer, which defaults to the root
+func (s *SchemaRegistry) UnmarshalJSON(data []byte) error {
+
+	names, named, err := decodeObject(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.reg == nil {
+		root := s.unmarshalFrom
+		if root == nil {
+			root = newPointerFromRefs(nil)
+		}
+
+		s.init(root)
+	}
+
+	for _, name := range names {
+		raw := named[name]
+		sch := NewSchema()
+
+		if err := sch.UnmarshalJSON(raw); err != nil {
+			return errors.New("error decoding schema " + name + ": " + err.Error())
+		}
+
+		s.setSchema(appendRefs(s.unmarshalFrom, name), sch)
+	}
+
+	return nil
+}
+
+// decodeObject decodes a json object, returning its keys in the order they appear
+func decodeObject(data []byte) ([]string, map[string]json.RawMessage, error) {
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	if tok, err := dec.Token(); err != nil {
+		return nil, nil, err
+	} else if tok != json.Delim('{') {
+		return nil, nil, errors.New("expected a json object")
+	}
+
+	var (
+		keys   []string
+		values = map[string]json.RawMessage{}
+	)
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		key := tok.(string)
+
+		var raw json.RawMessage
+
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, err
+		}
+
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = raw
+	}
+
+	return keys, values, nil
+}
+
+// UnmarshalJSON decodes every section of the components object in its registry; schemas are rooted at
+// #/components/schemas
+func (c *Components) UnmarshalJSON(data []byte) error {
+
+	var sections map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return err
+	}
+
+	fields := map[string]json.Unmarshaler{}
+
+	if _, ok := sections["schemas"]; ok {
+		c.Schemas, _ = NewRegistry(newPointerFromRefs([]string{"components", "schemas"}))
+		fields["schemas"] = c.Schemas
+	}
+	if _, ok := sections["contentDescriptors"]; ok {
+		c.ContentDescriptors = NewContentDescriptorRegistry()
+		fields["contentDescriptors"] = c.ContentDescriptors
+	}
+	if _, ok := sections["examples"]; ok {
+		c.Examples = NewExampleRegistry()
+		fields["examples"] = c.Examples
+	}
+	if _, ok := sections["links"]; ok {
+		c.Links = NewLinkRegistry()
+		fields["links"] = c.Links
+	}
+	if _, ok := sections["errors"]; ok {
+		c.Errors = NewErrorRegistry()
+		fields["errors"] = c.Errors
+	}
+	if _, ok := sections["examplePairingObjects"]; ok {
+		c.ExamplePairingObjects = NewExamplePairingRegistry()
+		fields["examplePairingObjects"] = c.ExamplePairingObjects
+	}
+	if _, ok := sections["tags"]; ok {
+		c.Tags = NewTagRegistry()
+		fields["tags"] = c.Tags
+	}
+
+	for name, field := range fields {
+		if err := field.UnmarshalJSON(sections[name]); err != nil {
+			return errors.New("error decoding components/" + name + ": " + err.Error())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package openrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// allExamples checks the example pairings of every method
+func (v *validator) allExamples(doc *DocumentSpec1) {
+
+	res, err := NewRefResolver(doc)
+	if err != nil {
+		v.add("", "cannot encode document: %v", err)
+		return
+	}
+
+	for i, m := range doc.Methods {
+		// references to components are checked by method
+		if resolved, err := doc.ResolveMethod(m); err == nil {
+			v.examples(fmt.Sprintf("methods/%d", i), resolved, res)
+		}
+	}
+}
+
+// examples checks that the example pairings of a method name declared params and that their values,
+// and the value of their result, validate against the schemas of the method
+func (v *validator) examples(path string, m *Method, res *RefResolver) {
+
+	for i, ex := range m.Examples {
+		epath := fmt.Sprintf("%s/examples/%d", path, i)
+
+		if ex == nil {
+			v.add(epath, "is null")
+			continue
+		}
+
+		name := ex.Name
+		if name == "" {
+			name = "#" + strconv.Itoa(i)
+		}
+		prefix := "example " + name + " of method " + m.Name + ": "
+
+		given := map[string]bool{}
+
+		for j, p := range ex.Params {
+			ppath := fmt.Sprintf("%s/params/%d", epath, j)
+
+			if p == nil {
+				v.add(ppath, prefix+"param is null")
+				continue
+			}
+
+			cd := m.ExampleParam(p, j)
+			if cd == nil {
+				pname := p.Name
+				if pname == "" {
+					pname = "#" + strconv.Itoa(j)
+				}
+				v.add(ppath, prefix+"param %s is not declared", pname)
+				continue
+			}
+			given[cd.Name] = true
+
+			if p.ExternalValue == "" {
+				v.value(ppath+"/value", prefix+"param "+cd.Name+": ", cd, p.Value, res)
+			}
+		}
+
+		for _, cd := range m.Params {
+			if cd != nil && cd.Required && !given[cd.Name] {
+				v.add(epath+"/params", prefix+"required param %s is missing", cd.Name)
+			}
+		}
+
+		if ex.Result != nil && ex.Result.ExternalValue == "" && m.Result != nil {
+			v.value(epath+"/result/value", prefix+"result: ", m.Result, ex.Result.Value, res)
+		}
+	}
+}
+
+// ExampleParam returns the param an example param stands for: the one with its name if the method takes params
+// by name, otherwise the one at its position
+func (m *Method) ExampleParam(p *Example, i int) *ContentDescriptor {
+
+	if p.Name != "" && m.ParamStructure == "by-name" {
+		for _, cd := range m.Params {
+			if cd != nil && cd.Name == p.Name {
+				return cd
+			}
+		}
+		return nil
+	}
+
+	if i < len(m.Params) {
+		return m.Params[i]
+	}
+
+	return nil
+}
+
+// value checks a value against the schema of a content descriptor; schemas with external references are not checked
+func (v *validator) value(path, prefix string, cd *ContentDescriptor, value interface{}, res *RefResolver) {
+
+	sch, err := res.Schema(cd)
+	if err != nil {
+		return
+	}
+
+	rs, err := res.Compile(sch)
+	if err == ErrExternalRef {
+		return
+	}
+	if err != nil {
+		v.add(path, prefix+"cannot compile schema: %v", err)
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		v.add(path, prefix+"cannot encode value: %v", err)
+		return
+	}
+
+	errs, err := rs.ValidateBytes(context.Background(), data)
+	if err != nil {
+		v.add(path, prefix+"%v", err)
+		return
+	}
+
+	if len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, e := range errs {
+			msgs[i] = e.Error()
+		}
+
+		v.add(path, prefix+"value %s does not match the schema: %s", data, strings.Join(msgs, "; "))
+	}
+}
@@ -0,0 +1,865 @@
+// Package fuzz generates random json values that conform, or deliberately do not conform, to json schemas,
+// for property based tests of the methods declared in openrpc documents
+package fuzz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	jsch "github.com/qri-io/jsonschema"
+)
+
+var (
+	// ErrNoValue is returned when a schema accepts no value, e.g. false
+	ErrNoValue = errors.New("schema accepts no value")
+	// ErrAnyValue is returned when an invalid value is asked for a schema that accepts every value, e.g. {}
+	ErrAnyValue = errors.New("schema accepts any value")
+)
+
+// Resolver returns the decoded schema a $ref points to; *openrpc.RefResolver implements it
+type Resolver interface {
+	Resolve(ref string) (interface{}, error)
+}
+
+type registryResolver struct {
+	reg *openrpc.SchemaRegistry
+}
+
+func (r registryResolver) Resolve(ref string) (interface{}, error) {
+
+	ptr, err := openrpc.NewPointer(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.reg.Resolve(ptr)
+}
+
+// RegistryResolver resolves references to the schemas registered in reg, e.g. #/components/schemas/Block
+func RegistryResolver(reg *openrpc.SchemaRegistry) Resolver {
+	return registryResolver{reg: reg}
+}
+
+// Generator generates random values from schemas; the same seed and schemas always produce the same values.
+// A Generator is not safe for concurrent use
+type Generator struct {
+	// MaxDepth is the nesting depth past which optional properties and items are no longer generated, 4 by default
+	MaxDepth int
+	// MaxItems bounds arrays that declare no maxItems, 4 by default
+	MaxItems int
+	// Examples makes schemas declaring a default or examples generate the default, or their first example
+	Examples bool
+
+	rnd   *rand.Rand
+	res   Resolver
+	depth int
+}
+
+// maxAttempts bounds the values generated until one meets the constraints generation does not ensure,
+// e.g. the length bounds of a pattern, or oneOf
+const maxAttempts = 20
+
+// maxRecursion bounds the depth of values that keep nesting past MaxDepth, because of required recursive properties
+const maxRecursion = 32
+
+// New returns a Generator seeded with seed, resolving references with res; res can be nil if schemas have no $ref
+func New(seed int64, res Resolver) *Generator {
+	return &Generator{MaxDepth: 4, MaxItems: 4, rnd: rand.New(rand.NewSource(seed)), res: res}
+}
+
+// Valid returns a random value conforming to sch, which is either a decoded json schema or a value encoding to one,
+// such as an openrpc.Schema or an openrpc.Pointer
+func (g *Generator) Valid(sch interface{}) (interface{}, error) {
+
+	m, err := decode(sch)
+	if err != nil {
+		return nil, err
+	}
+
+	g.depth = 0
+
+	return g.valid(m)
+}
+
+// Params returns random params of m: an array, or an object if its param structure is by-name.
+// Optional params are left out at random, only at the end of the array for by-position params.
+// References to components/contentDescriptors must be resolved first, see DocumentSpec1.ResolveMethod
+func (g *Generator) Params(m *openrpc.Method) (interface{}, error) {
+
+	values := make([]interface{}, 0, len(m.Params))
+	named := map[string]interface{}{}
+	omit := false
+
+	for _, cd := range m.Params {
+		if !cd.Required && g.rnd.Intn(3) == 0 {
+			omit = true
+			continue
+		}
+
+		if omit && m.ParamStructure != "by-name" {
+			break
+		}
+
+		v, err := g.Valid(schemaOf(cd))
+		if err != nil {
+			return nil, errors.New("param " + cd.Name + ": " + err.Error())
+		}
+
+		values = append(values, v)
+		named[cd.Name] = v
+	}
+
+	if m.ParamStructure == "by-name" {
+		return named, nil
+	}
+
+	return values, nil
+}
+
+func schemaOf(cd *openrpc.ContentDescriptor) interface{} {
+
+	if cd.Schema != nil {
+		return cd.Schema
+	}
+
+	if cd.InlineSchema != nil {
+		return cd.InlineSchema
+	}
+
+	// a content descriptor without schema accepts anything
+	return true
+}
+
+// decode returns the decoded json of a schema
+func decode(sch interface{}) (interface{}, error) {
+
+	switch sch.(type) {
+	case map[string]interface{}, bool:
+		return sch, nil
+	}
+
+	data, err := json.Marshal(sch)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (g *Generator) resolve(ref string) (interface{}, error) {
+
+	if g.res == nil {
+		return nil, errors.New("cannot resolve " + ref + " without a resolver")
+	}
+
+	return g.res.Resolve(ref)
+}
+
+func (g *Generator) valid(sch interface{}) (interface{}, error) {
+
+	if b, ok := sch.(bool); ok {
+		if !b {
+			return nil, ErrNoValue
+		}
+		return g.any(), nil
+	}
+
+	m, ok := sch.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid schema")
+	}
+
+	if g.depth > g.MaxDepth+maxRecursion {
+		return nil, errors.New("schema recursion too deep")
+	}
+
+	g.depth++
+	defer func() { g.depth-- }()
+
+	if ref, ok := openrpc.RefOf(m); ok {
+		target, err := g.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		return g.valid(target)
+	}
+
+	if !checked(m) {
+		return g.generate(m)
+	}
+
+	rs, err := openrpc.CompileSchema(m, g.resolve)
+	if err != nil {
+		return nil, errors.New("cannot check values against the schema: " + err.Error())
+	}
+
+	for i := 0; i < maxAttempts; i++ {
+		v, err := g.generate(m)
+		if err != nil {
+			return nil, err
+		}
+
+		ok, err := conforms(rs, v)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return v, nil
+		}
+	}
+
+	return nil, errors.New("cannot generate a value conforming to oneOf, not or multipleOf")
+}
+
+// checked reports whether the values generated from a schema must be checked against it: generation picks an
+// alternative of oneOf without excluding the others, ignores not, and computes multiples that can be off by rounding
+func checked(m map[string]interface{}) bool {
+
+	for _, key := range []string{"oneOf", "not", "multipleOf"} {
+		if _, ok := m[key]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// conforms reports whether a value validates against a compiled schema; it fails if the value cannot be checked
+func conforms(rs *jsch.Schema, v interface{}) (ok bool, err error) {
+
+	// the validator panics on some schemas, e.g. an empty enum
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("cannot check value")
+		}
+	}()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+
+	errs, err := rs.ValidateBytes(context.Background(), data)
+	if err != nil {
+		return false, err
+	}
+
+	return len(errs) == 0, nil
+}
+
+// generate returns a value from the keywords of a schema
+func (g *Generator) generate(m map[string]interface{}) (interface{}, error) {
+
+	if v, ok := m["const"]; ok {
+		return v, nil
+	}
+
+	if g.Examples {
+		if v, ok := m["default"]; ok {
+			return v, nil
+		}
+
+		if examples, ok := m["examples"].([]interface{}); ok && len(examples) > 0 {
+			return examples[0], nil
+		}
+	}
+
+	if enum, ok := m["enum"].([]interface{}); ok {
+		if len(enum) == 0 {
+			return nil, ErrNoValue
+		}
+		return enum[g.rnd.Intn(len(enum))], nil
+	}
+
+	if all, ok := m["allOf"].([]interface{}); ok && len(all) > 0 {
+		merged, err := g.merge(m, all)
+		if err != nil {
+			return nil, err
+		}
+		return g.valid(merged)
+	}
+
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alts, ok := m[key].([]interface{}); ok && len(alts) > 0 {
+			// an alternative is generated together with the keywords next to it
+			sub := make(map[string]interface{}, len(m))
+			for k, v := range m {
+				if k != key {
+					sub[k] = v
+				}
+			}
+			sub["allOf"] = []interface{}{alts[g.rnd.Intn(len(alts))]}
+
+			return g.valid(sub)
+		}
+	}
+
+	switch g.typeOf(m) {
+	case "null":
+		return nil, nil
+	case "boolean":
+		return g.rnd.Intn(2) == 0, nil
+	case "integer":
+		return g.integer(m)
+	case "number":
+		return g.number(m)
+	case "string":
+		return g.string(m)
+	case "array":
+		return g.array(m)
+	case "object":
+		return g.object(m)
+	}
+
+	return g.any(), nil
+}
+
+// merge combines the subschemas of allOf with the schema declaring them: properties are joined, required lists
+// are concatenated and other keywords are overwritten by later subschemas
+func (g *Generator) merge(m map[string]interface{}, all []interface{}) (map[string]interface{}, error) {
+
+	merged := map[string]interface{}{}
+	props := map[string]interface{}{}
+	var required []interface{}
+
+	add := func(sub map[string]interface{}) {
+		for k, v := range sub {
+			switch k {
+			case "allOf":
+			case "properties":
+				p, _ := v.(map[string]interface{})
+				for name, sch := range p {
+					props[name] = sch
+				}
+			case "required":
+				r, _ := v.([]interface{})
+				required = append(required, r...)
+			default:
+				merged[k] = v
+			}
+		}
+	}
+
+	add(m)
+
+	for _, sub := range all {
+		sub, err := g.deref(sub)
+		if err != nil {
+			return nil, err
+		}
+
+		if b, ok := sub.(bool); ok {
+			if !b {
+				return nil, ErrNoValue
+			}
+			continue
+		}
+
+		sm, _ := sub.(map[string]interface{})
+
+		if nested, ok := sm["allOf"].([]interface{}); ok {
+			if sm, err = g.merge(sm, nested); err != nil {
+				return nil, err
+			}
+		}
+
+		add(sm)
+	}
+
+	if len(props) > 0 {
+		merged["properties"] = props
+	}
+
+	if len(required) > 0 {
+		merged["required"] = required
+	}
+
+	return merged, nil
+}
+
+// deref follows the $ref chain of a schema
+func (g *Generator) deref(sch interface{}) (interface{}, error) {
+
+	for i := 0; ; i++ {
+		ref, ok := openrpc.RefOf(sch)
+		if !ok {
+			return sch, nil
+		}
+
+		if i > maxRecursion {
+			return nil, errors.New("circular reference " + ref)
+		}
+
+		target, err := g.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		sch = target
+	}
+}
+
+// typeOf picks one of the types of a schema, inferring it from the keywords if there is none
+func (g *Generator) typeOf(m map[string]interface{}) string {
+
+	if types := typesOf(m); len(types) > 0 {
+		return types[g.rnd.Intn(len(types))]
+	}
+
+	switch {
+	case m["properties"] != nil || m["required"] != nil:
+		return "object"
+	case m["items"] != nil:
+		return "array"
+	case m["minLength"] != nil || m["maxLength"] != nil || m["pattern"] != nil || m["format"] != nil:
+		return "string"
+	case m["minimum"] != nil || m["maximum"] != nil || m["multipleOf"] != nil:
+		return "number"
+	}
+
+	return ""
+}
+
+func typesOf(m map[string]interface{}) []string {
+
+	switch t := m["type"].(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+
+	return nil
+}
+
+// any returns a random scalar
+func (g *Generator) any() interface{} {
+
+	switch g.rnd.Intn(5) {
+	case 0:
+		return nil
+	case 1:
+		return g.rnd.Intn(2) == 0
+	case 2:
+		return int64(g.rnd.Intn(2001) - 1000)
+	case 3:
+		return g.rnd.Float64()*2000 - 1000
+	}
+
+	return g.randomString(0, 8)
+}
+
+// span is the width of the range numbers are picked from when a schema bounds them on one side only, or not at all
+const span = 1000
+
+// bounds returns the inclusive range allowed by minimum, maximum and their exclusive variants;
+// exclusive bounds are moved by step, or by a small fraction for numbers
+func bounds(m map[string]interface{}, step float64) (float64, float64, error) {
+
+	lo, hasLo := m["minimum"].(float64)
+	hi, hasHi := m["maximum"].(float64)
+
+	// draft 4 exclusive bounds are booleans
+	if b, _ := m["exclusiveMinimum"].(bool); b && hasLo {
+		lo += step
+	}
+	if b, _ := m["exclusiveMaximum"].(bool); b && hasHi {
+		hi -= step
+	}
+
+	if v, ok := m["exclusiveMinimum"].(float64); ok && (!hasLo || v+step > lo) {
+		lo, hasLo = v+step, true
+	}
+	if v, ok := m["exclusiveMaximum"].(float64); ok && (!hasHi || v-step < hi) {
+		hi, hasHi = v-step, true
+	}
+
+	switch {
+	case !hasLo && !hasHi:
+		lo, hi = -span, span
+	case !hasLo:
+		lo = hi - 2*span
+	case !hasHi:
+		hi = lo + 2*span
+	}
+
+	if lo > hi {
+		return 0, 0, ErrNoValue
+	}
+
+	return lo, hi, nil
+}
+
+func (g *Generator) integer(m map[string]interface{}) (interface{}, error) {
+
+	lo, hi, err := bounds(m, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	lo, hi = math.Ceil(lo), math.Floor(hi)
+
+	if mult, ok := m["multipleOf"].(float64); ok && mult > 0 {
+		klo, khi := math.Ceil(lo/mult), math.Floor(hi/mult)
+		if klo > khi {
+			return nil, ErrNoValue
+		}
+
+		v := round((klo+float64(g.rnd.Int63n(int64(khi-klo)+1)))*mult, decimals(mult))
+		if v != math.Trunc(v) {
+			return nil, ErrNoValue
+		}
+
+		return int64(v), nil
+	}
+
+	if lo > hi {
+		return nil, ErrNoValue
+	}
+
+	return int64(lo) + g.rnd.Int63n(int64(hi-lo)+1), nil
+}
+
+func (g *Generator) number(m map[string]interface{}) (interface{}, error) {
+
+	lo, hi, err := bounds(m, 1e-6)
+	if err != nil {
+		return nil, err
+	}
+
+	if mult, ok := m["multipleOf"].(float64); ok && mult > 0 {
+		klo, khi := math.Ceil(lo/mult), math.Floor(hi/mult)
+		if klo > khi {
+			return nil, ErrNoValue
+		}
+
+		return round((klo+float64(g.rnd.Int63n(int64(khi-klo)+1)))*mult, decimals(mult)), nil
+	}
+
+	return lo + g.rnd.Float64()*(hi-lo), nil
+}
+
+// decimals returns the number of decimals of the shortest representation of f
+func decimals(f float64) int {
+
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+
+	return 0
+}
+
+// round rounds f to n decimals, e.g. 6 * 0.1 to 0.6
+func round(f float64, n int) float64 {
+
+	v, err := strconv.ParseFloat(strconv.FormatFloat(f, 'f', n, 64), 64)
+	if err != nil {
+		return f
+	}
+
+	return v
+}
+
+// formats generate values of the string formats defined by json schema
+var formats = map[string]func(rnd *rand.Rand) string{
+	"date-time": func(rnd *rand.Rand) string { return randomTime(rnd).Format(time.RFC3339) },
+	"date":      func(rnd *rand.Rand) string { return randomTime(rnd).Format("2006-01-02") },
+	"time":      func(rnd *rand.Rand) string { return randomTime(rnd).Format("15:04:05Z07:00") },
+	"email":     func(rnd *rand.Rand) string { return "user" + strconv.Itoa(rnd.Intn(1000)) + "@example.com" },
+	"hostname":  func(rnd *rand.Rand) string { return "host" + strconv.Itoa(rnd.Intn(1000)) + ".example.com" },
+	"ipv4": func(rnd *rand.Rand) string {
+		return strconv.Itoa(rnd.Intn(256)) + "." + strconv.Itoa(rnd.Intn(256)) + "." + strconv.Itoa(rnd.Intn(256)) + "." + strconv.Itoa(rnd.Intn(256))
+	},
+	"ipv6": func(rnd *rand.Rand) string { return "2001:db8::" + strconv.FormatInt(int64(rnd.Intn(0x10000)), 16) },
+	"uri":  func(rnd *rand.Rand) string { return "https://example.com/" + strconv.Itoa(rnd.Intn(1000)) },
+	"uuid": func(rnd *rand.Rand) string {
+		b := []byte("xxxxxxxx-xxxx-4xxx-8xxx-xxxxxxxxxxxx")
+		for i, c := range b {
+			if c == 'x' {
+				b[i] = "0123456789abcdef"[rnd.Intn(16)]
+			}
+		}
+		return string(b)
+	},
+}
+
+func randomTime(rnd *rand.Rand) time.Time {
+	return time.Unix(rnd.Int63n(4102444800), 0).UTC()
+}
+
+func (g *Generator) string(m map[string]interface{}) (interface{}, error) {
+
+	min, max := 0, -1
+	if v, ok := m["minLength"].(float64); ok {
+		min = int(v)
+	}
+	if v, ok := m["maxLength"].(float64); ok {
+		max = int(v)
+	}
+
+	if max >= 0 && min > max {
+		return nil, ErrNoValue
+	}
+
+	if pattern, ok := m["pattern"].(string); ok {
+		for i := 0; i < maxAttempts; i++ {
+			s, err := matching(g.rnd, pattern)
+			if err != nil {
+				return nil, err
+			}
+
+			if n := len([]rune(s)); n >= min && (max < 0 || n <= max) {
+				return s, nil
+			}
+		}
+
+		return nil, errors.New("cannot generate a string matching " + pattern + " within its length bounds")
+	}
+
+	if format, ok := m["format"].(string); ok {
+		if f, ok := formats[format]; ok {
+			return f(g.rnd), nil
+		}
+	}
+
+	if max < 0 {
+		max = min + 8
+	}
+
+	return g.randomString(min, max), nil
+}
+
+func (g *Generator) randomString(min, max int) string {
+
+	n := min + g.rnd.Intn(max-min+1)
+	runes := make([]rune, n)
+
+	for i := range runes {
+		runes[i] = printable[g.rnd.Intn(len(printable))]
+	}
+
+	return string(runes)
+}
+
+func (g *Generator) array(m map[string]interface{}) (interface{}, error) {
+
+	min, max := 0, -1
+	if v, ok := m["minItems"].(float64); ok {
+		min = int(v)
+	}
+	if v, ok := m["maxItems"].(float64); ok {
+		max = int(v)
+	}
+
+	if max >= 0 && min > max {
+		return nil, ErrNoValue
+	}
+
+	if max < 0 || max > min+g.MaxItems {
+		max = min + g.MaxItems
+	}
+
+	n := min
+	if g.depth <= g.MaxDepth {
+		n += g.rnd.Intn(max - min + 1)
+	}
+
+	unique, _ := m["uniqueItems"].(bool)
+	tuple, isTuple := m["items"].([]interface{})
+
+	arr := make([]interface{}, 0, n)
+
+	for i := 0; len(arr) < n; i++ {
+		var item interface{} = true
+
+		switch {
+		case isTuple && len(arr) < len(tuple):
+			item = tuple[len(arr)]
+		case isTuple:
+			if additional, ok := m["additionalItems"]; ok {
+				item = additional
+			}
+		case m["items"] != nil:
+			item = m["items"]
+		}
+
+		v, err := g.valid(item)
+		if err != nil {
+			return nil, err
+		}
+
+		if unique && contains(arr, v) {
+			if i > n*maxAttempts {
+				return nil, errors.New("cannot generate enough unique items")
+			}
+			continue
+		}
+
+		arr = append(arr, v)
+	}
+
+	return arr, nil
+}
+
+func (g *Generator) object(m map[string]interface{}) (interface{}, error) {
+
+	obj := map[string]interface{}{}
+	props, _ := m["properties"].(map[string]interface{})
+
+	required := map[string]bool{}
+	for _, name := range requiredOf(m) {
+		required[name] = true
+	}
+
+	maxProps := -1
+	if v, ok := m["maxProperties"].(float64); ok {
+		maxProps = int(v)
+	}
+
+	// required properties first, then optional ones in a stable order, so that a seed always produces the same value
+	names := make([]string, 0, len(props)+len(required))
+	for name := range required {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	optional := make([]string, 0, len(props))
+	for name := range props {
+		if !required[name] {
+			optional = append(optional, name)
+		}
+	}
+	sort.Strings(optional)
+
+	for _, name := range names {
+		sch, ok := props[name]
+		if !ok {
+			sch = additionalSchema(m)
+		}
+
+		v, err := g.valid(sch)
+		if err != nil {
+			return nil, errors.New(name + ": " + err.Error())
+		}
+
+		obj[name] = v
+	}
+
+	for _, name := range optional {
+		if g.depth > g.MaxDepth || g.rnd.Intn(2) == 0 || (maxProps >= 0 && len(obj) >= maxProps) {
+			continue
+		}
+
+		v, err := g.valid(props[name])
+		if err == ErrNoValue {
+			continue
+		}
+		if err != nil {
+			return nil, errors.New(name + ": " + err.Error())
+		}
+
+		obj[name] = v
+	}
+
+	if min, ok := m["minProperties"].(float64); ok {
+		for i := 0; len(obj) < int(min); i++ {
+			name := "property" + strconv.Itoa(i)
+			if _, ok := obj[name]; ok {
+				continue
+			}
+
+			sch, ok := props[name]
+			if !ok {
+				sch = additionalSchema(m)
+			}
+
+			v, err := g.valid(sch)
+			if err != nil {
+				return nil, errors.New(name + ": " + err.Error())
+			}
+
+			obj[name] = v
+		}
+	}
+
+	return obj, nil
+}
+
+// additionalSchema returns the schema of properties that are not declared by an object schema
+func additionalSchema(m map[string]interface{}) interface{} {
+
+	if additional, ok := m["additionalProperties"]; ok {
+		return additional
+	}
+
+	return true
+}
+
+func requiredOf(m map[string]interface{}) []string {
+
+	list, _ := m["required"].([]interface{})
+	names := make([]string, 0, len(list))
+
+	for _, item := range list {
+		if name, ok := item.(string); ok {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func contains(values []interface{}, v interface{}) bool {
+
+	for _, other := range values {
+		if equal(other, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equal compares json values, regardless of their go representation (e.g. int64 or float64 numbers)
+func equal(a, b interface{}) bool {
+
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && string(da) == string(db)
+}
+
+// matchesPattern reports whether s matches a pattern, invalid patterns match nothing
+func matchesPattern(pattern, s string) bool {
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+
+	return re.MatchString(s)
+}
@@ -0,0 +1,318 @@
+package fuzz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	"github.com/octanolabs/g0penrpc/internal/testutil"
+	jsch "github.com/qri-io/jsonschema"
+)
+
+// seeds is the number of seeds every test generates values with
+const seeds = 200
+
+// validate reports the errors of v against a schema without references
+func validate(t *testing.T, sch interface{}, v interface{}) []jsch.KeyError {
+	t.Helper()
+
+	data, err := json.Marshal(sch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs := &jsch.Schema{}
+	if err := json.Unmarshal(data, rs); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err = json.Marshal(v); err != nil {
+		t.Fatal(err)
+	}
+
+	errs, err := rs.ValidateBytes(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return errs
+}
+
+func TestGenerator(t *testing.T) {
+
+	doc := testutil.Document(t, "shop.json")
+
+	res, err := openrpc.NewRefResolver(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// values are generated from the referencing document and validated against the dereferenced one,
+	// which cannot hold the recursive Tree schema
+	acyclic := testutil.Document(t, "shop.json")
+
+	tree, _ := openrpc.NewPointer("/components/schemas/Tree")
+	acyclic.Components.Schemas.Remove(tree)
+
+	deref, err := openrpc.Dereference(acyclic, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, m := range doc.Methods {
+		cds := append(m.Params, m.Result)
+
+		for j, cd := range cds {
+			inline := append(deref.Methods[i].Params, deref.Methods[i].Result)[j].InlineSchema
+
+			t.Run(m.Name+"/"+cd.Name, func(t *testing.T) {
+				for seed := int64(0); seed < seeds; seed++ {
+					g := New(seed, res)
+
+					v, err := g.Valid(schemaOf(cd))
+					if err != nil {
+						t.Fatalf("error, seed %d: %v", seed, err)
+					}
+
+					if errs := validate(t, inline, v); len(errs) > 0 {
+						data, _ := json.Marshal(v)
+						t.Fatalf("error, seed %d: generated invalid value %s: %v", seed, data, errs)
+					}
+
+					v, err = g.Invalid(schemaOf(cd))
+					if err != nil {
+						t.Fatalf("error, seed %d: %v", seed, err)
+					}
+
+					if errs := validate(t, inline, v); len(errs) == 0 {
+						data, _ := json.Marshal(v)
+						t.Fatalf("error, seed %d: generated valid value %s", seed, data)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestParams(t *testing.T) {
+
+	doc := testutil.Document(t, "shop.json")
+
+	res, err := openrpc.NewRefResolver(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, quote := doc.Methods[0], doc.Methods[1]
+	omitted := map[string]bool{}
+
+	for seed := int64(0); seed < seeds; seed++ {
+		g := New(seed, res)
+
+		v, err := g.Params(order)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		named, ok := v.(map[string]interface{})
+		if !ok {
+			t.Fatalf("error, expected by-name params, got %T", v)
+		}
+		if _, ok := named["order"]; !ok {
+			t.Fatalf("error, required param missing from %v", named)
+		}
+		if _, ok := named["coupon"]; !ok {
+			omitted["coupon"] = true
+		}
+
+		if v, err = g.Params(quote); err != nil {
+			t.Fatal(err)
+		}
+
+		if positional, ok := v.([]interface{}); !ok || len(positional) == 0 {
+			t.Fatalf("error, expected by-position params with the required sku, got %v", v)
+		}
+
+		if v, err = g.InvalidParams(quote); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := v.([]interface{}); !ok {
+			t.Fatalf("error, expected by-position params, got %T", v)
+		}
+	}
+
+	if !omitted["coupon"] {
+		t.Error("error, optional params are never omitted")
+	}
+}
+
+func TestDeterminism(t *testing.T) {
+
+	doc := testutil.Document(t, "shop.json")
+
+	res, err := openrpc.NewRefResolver(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generate := func(seed int64) []interface{} {
+		g := New(seed, res)
+
+		var values []interface{}
+
+		for i := 0; i < 10; i++ {
+			v, err := g.Params(doc.Methods[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			values = append(values, v)
+		}
+
+		return values
+	}
+
+	if !reflect.DeepEqual(generate(42), generate(42)) {
+		t.Error("error, the same seed generated different values")
+	}
+
+	if reflect.DeepEqual(generate(42), generate(43)) {
+		t.Error("error, different seeds generated the same values")
+	}
+}
+
+func TestRecursiveSchema(t *testing.T) {
+
+	doc := testutil.Document(t, "shop.json")
+
+	reg := doc.Components.Schemas
+
+	for seed := int64(0); seed < seeds; seed++ {
+		g := New(seed, RegistryResolver(reg))
+
+		v, err := g.Valid(map[string]interface{}{"$ref": "#/components/schemas/Tree"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if depth := treeDepth(v); depth > g.MaxDepth+1 {
+			t.Fatalf("error, tree of depth %d exceeds MaxDepth", depth)
+		}
+	}
+}
+
+func treeDepth(v interface{}) int {
+
+	tree, _ := v.(map[string]interface{})
+	children, _ := tree["children"].([]interface{})
+
+	depth := 0
+	for _, child := range children {
+		if d := treeDepth(child); d > depth {
+			depth = d
+		}
+	}
+
+	return depth + 1
+}
+
+func TestUnsatisfiable(t *testing.T) {
+
+	g := New(1, nil)
+
+	for _, c := range []struct {
+		schema  string
+		valid   error
+		invalid error
+	}{
+		{`false`, ErrNoValue, nil},
+		{`true`, nil, ErrAnyValue},
+		{`{}`, nil, ErrAnyValue},
+		{`{"type":"integer","minimum":3,"maximum":2}`, ErrNoValue, nil},
+		{`{"type":"string","minLength":3,"maxLength":2}`, ErrNoValue, nil},
+		{`{"enum":[]}`, ErrNoValue, nil},
+		{`{"description":"no constraint"}`, nil, ErrAnyValue},
+	} {
+		var sch interface{}
+		if err := json.Unmarshal([]byte(c.schema), &sch); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := g.Valid(sch); !errors.Is(err, c.valid) {
+			t.Errorf("error, valid value of %s: expected %v, got %v", c.schema, c.valid, err)
+		}
+
+		if _, err := g.Invalid(sch); !errors.Is(err, c.invalid) {
+			t.Errorf("error, invalid value of %s: expected %v, got %v", c.schema, c.invalid, err)
+		}
+	}
+}
+
+// TestUnchecked checks that values which cannot be checked against their schema are not returned as valid
+func TestUnchecked(t *testing.T) {
+
+	g := New(1, nil)
+
+	for _, schema := range []string{
+		`{"not":{"enum":[]}}`,
+		`{"not":{"type":"text"}}`,
+		`{"oneOf":[{"$ref":"#/components/schemas/Missing"}]}`,
+	} {
+		var sch interface{}
+		if err := json.Unmarshal([]byte(schema), &sch); err != nil {
+			t.Fatal(err)
+		}
+
+		if v, err := g.Valid(sch); err == nil {
+			t.Errorf("error, expected no valid value of %s, got %v", schema, v)
+		}
+	}
+}
+
+// TestProperties checks that valid values validate, and invalid values do not, for schemas whose constraints
+// generation does not ensure on its own
+func TestProperties(t *testing.T) {
+
+	for _, schema := range []string{
+		`{"type":"number","multipleOf":0.1,"minimum":0,"maximum":10}`,
+		`{"type":"number","multipleOf":0.01,"exclusiveMinimum":-1,"maximum":1}`,
+		`{"type":"integer","multipleOf":3,"minimum":-20,"maximum":20}`,
+		`{"oneOf":[{"type":"integer","minimum":0},{"type":"integer","maximum":10}]}`,
+		`{"oneOf":[{"type":"string"},{"type":"string","maxLength":2}]}`,
+		`{"type":"integer","minimum":0,"maximum":5,"not":{"enum":[1,2,3]}}`,
+		`{"not":{"type":["string","null","boolean"]}}`,
+		`{"type":"object","required":["a"],"properties":{"a":{"oneOf":[{"type":"number","multipleOf":0.5},{"type":"string","pattern":"^x+$"}]}}}`,
+		`{"type":"array","minItems":1,"items":{"type":"string","not":{"pattern":"a"}}}`,
+	} {
+		var sch interface{}
+		if err := json.Unmarshal([]byte(schema), &sch); err != nil {
+			t.Fatal(err)
+		}
+
+		for seed := int64(0); seed < seeds; seed++ {
+			g := New(seed, nil)
+
+			v, err := g.Valid(sch)
+			if err != nil {
+				t.Fatalf("error, valid value of %s with seed %d: %v", schema, seed, err)
+			}
+
+			if errs := validate(t, sch, v); len(errs) > 0 {
+				t.Fatalf("error, valid value %v of %s with seed %d does not validate: %v", v, schema, seed, errs)
+			}
+
+			v, err = g.Invalid(sch)
+			if err != nil {
+				t.Fatalf("error, invalid value of %s with seed %d: %v", schema, seed, err)
+			}
+
+			if errs := validate(t, sch, v); len(errs) == 0 {
+				t.Fatalf("error, invalid value %v of %s with seed %d validates", v, schema, seed)
+			}
+		}
+	}
+}
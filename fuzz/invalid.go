@@ -0,0 +1,505 @@
+package fuzz
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+// Invalid returns a random value that does not conform to sch, by breaking one of its constraints at random:
+// its type, enum, bounds, pattern, lengths, required properties, items or properties. It returns ErrAnyValue
+// if sch has no constraint that can be broken
+func (g *Generator) Invalid(sch interface{}) (interface{}, error) {
+
+	m, err := decode(sch)
+	if err != nil {
+		return nil, err
+	}
+
+	g.depth = 0
+
+	rs, err := openrpc.CompileSchema(m, g.resolve)
+	if err != nil {
+		// the values of schemas that cannot be compiled are not checked
+		return g.invalid(m)
+	}
+
+	// breaking a constraint does not always break the schema, e.g. a value of the wrong type for one alternative of anyOf
+	for i := 0; i < maxAttempts; i++ {
+		v, err := g.invalid(m)
+		if err != nil {
+			return nil, err
+		}
+
+		// values that cannot be checked are returned as well
+		if ok, _ := conforms(rs, v); !ok {
+			return v, nil
+		}
+	}
+
+	return nil, errors.New("cannot generate a value breaking the schema")
+}
+
+// InvalidParams returns params of m that break the schema of one of them, or leave out a required one
+func (g *Generator) InvalidParams(m *openrpc.Method) (interface{}, error) {
+
+	var candidates []int
+
+	for i, cd := range m.Params {
+		if cd.Required {
+			candidates = append(candidates, i)
+			continue
+		}
+
+		// an optional param can only be broken through its schema
+		if _, err := g.Invalid(schemaOf(cd)); err == nil {
+			candidates = append(candidates, i)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, ErrAnyValue
+	}
+
+	broken := candidates[g.rnd.Intn(len(candidates))]
+
+	values := make([]interface{}, 0, len(m.Params))
+	named := map[string]interface{}{}
+
+	for i, cd := range m.Params {
+		var (
+			v   interface{}
+			err error
+		)
+
+		switch {
+		case i != broken:
+			v, err = g.Valid(schemaOf(cd))
+		case m.ParamStructure == "by-name" && g.rnd.Intn(2) == 0:
+			// leave out the required param, by-position params can only leave out the last ones
+			continue
+		default:
+			if v, err = g.Invalid(schemaOf(cd)); err == ErrAnyValue {
+				if m.ParamStructure == "by-name" {
+					continue
+				}
+				// a missing required param is the only way to break it
+				return values, nil
+			}
+		}
+
+		if err != nil {
+			return nil, errors.New("param " + cd.Name + ": " + err.Error())
+		}
+
+		values = append(values, v)
+		named[cd.Name] = v
+	}
+
+	if m.ParamStructure == "by-name" {
+		return named, nil
+	}
+
+	return values, nil
+}
+
+// mutation produces a value breaking one constraint of a schema
+type mutation func() (interface{}, error)
+
+func (g *Generator) invalid(sch interface{}) (interface{}, error) {
+
+	if b, ok := sch.(bool); ok {
+		if b {
+			return nil, ErrAnyValue
+		}
+		return g.any(), nil
+	}
+
+	m, ok := sch.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid schema")
+	}
+
+	if g.depth > g.MaxDepth+maxRecursion {
+		return nil, errors.New("schema recursion too deep")
+	}
+
+	g.depth++
+	defer func() { g.depth-- }()
+
+	if ref, ok := openrpc.RefOf(m); ok {
+		target, err := g.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		return g.invalid(target)
+	}
+
+	mutations := g.mutations(m)
+
+	// mutations are tried in random order, until one of them applies
+	for _, i := range g.rnd.Perm(len(mutations)) {
+		v, err := mutations[i]()
+		if err == ErrAnyValue || err == ErrNoValue {
+			continue
+		}
+
+		return v, err
+	}
+
+	return nil, ErrAnyValue
+}
+
+func (g *Generator) mutations(m map[string]interface{}) []mutation {
+
+	var mutations []mutation
+
+	if types := typesOf(m); len(types) > 0 {
+		mutations = append(mutations, func() (interface{}, error) { return g.wrongType(types) })
+	}
+
+	if v, ok := m["const"]; ok {
+		mutations = append(mutations, func() (interface{}, error) { return g.notIn([]interface{}{v}), nil })
+	}
+
+	if enum, ok := m["enum"].([]interface{}); ok {
+		mutations = append(mutations, func() (interface{}, error) { return g.notIn(enum), nil })
+	}
+
+	if all, ok := m["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			sub := sub
+			mutations = append(mutations, func() (interface{}, error) { return g.invalid(sub) })
+		}
+	}
+
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alts, ok := m[key].([]interface{}); ok {
+			mutations = append(mutations, func() (interface{}, error) { return g.notAnyOf(alts) })
+		}
+	}
+
+	if not, ok := m["not"]; ok {
+		mutations = append(mutations, func() (interface{}, error) { return g.valid(not) })
+	}
+
+	mutations = append(mutations, g.numberMutations(m)...)
+	mutations = append(mutations, g.stringMutations(m)...)
+	mutations = append(mutations, g.arrayMutations(m)...)
+	mutations = append(mutations, g.objectMutations(m)...)
+
+	return mutations
+}
+
+// wrongType returns a value of none of types
+func (g *Generator) wrongType(types []string) (interface{}, error) {
+
+	allowed := map[string]bool{}
+	for _, t := range types {
+		allowed[t] = true
+	}
+
+	var values []interface{}
+
+	if !allowed["null"] {
+		values = append(values, nil)
+	}
+	if !allowed["boolean"] {
+		values = append(values, g.rnd.Intn(2) == 0)
+	}
+	if !allowed["integer"] && !allowed["number"] {
+		values = append(values, int64(g.rnd.Intn(2001)-1000))
+	}
+	if !allowed["number"] {
+		values = append(values, float64(g.rnd.Intn(2001)-1000)+0.5)
+	}
+	if !allowed["string"] {
+		values = append(values, g.randomString(0, 8))
+	}
+	if !allowed["array"] {
+		values = append(values, []interface{}{})
+	}
+	if !allowed["object"] {
+		values = append(values, map[string]interface{}{})
+	}
+
+	if len(values) == 0 {
+		return nil, ErrAnyValue
+	}
+
+	return values[g.rnd.Intn(len(values))], nil
+}
+
+// notIn returns a value that is none of values
+func (g *Generator) notIn(values []interface{}) interface{} {
+
+	for {
+		v := g.any()
+		if !contains(values, v) {
+			return v
+		}
+	}
+}
+
+// notAnyOf returns a value of a type none of the alternatives accept; it only applies when every alternative declares its types
+func (g *Generator) notAnyOf(alts []interface{}) (interface{}, error) {
+
+	var types []string
+
+	for _, alt := range alts {
+		alt, err := g.deref(alt)
+		if err != nil {
+			return nil, err
+		}
+
+		am, _ := alt.(map[string]interface{})
+
+		t := typesOf(am)
+		if len(t) == 0 {
+			return nil, ErrAnyValue
+		}
+
+		types = append(types, t...)
+	}
+
+	return g.wrongType(types)
+}
+
+func (g *Generator) numberMutations(m map[string]interface{}) []mutation {
+
+	integer := false
+	if types := typesOf(m); len(types) == 1 && types[0] == "integer" {
+		integer = true
+	}
+
+	num := func(v float64) interface{} {
+		if integer {
+			return int64(v)
+		}
+		return v
+	}
+
+	var mutations []mutation
+
+	if v, ok := m["minimum"].(float64); ok {
+		if b, _ := m["exclusiveMinimum"].(bool); b {
+			mutations = append(mutations, func() (interface{}, error) { return num(v), nil })
+		} else {
+			mutations = append(mutations, func() (interface{}, error) { return num(v - 1 - float64(g.rnd.Intn(span))), nil })
+		}
+	}
+
+	if v, ok := m["exclusiveMinimum"].(float64); ok {
+		mutations = append(mutations, func() (interface{}, error) { return num(v - float64(g.rnd.Intn(span))), nil })
+	}
+
+	if v, ok := m["maximum"].(float64); ok {
+		if b, _ := m["exclusiveMaximum"].(bool); b {
+			mutations = append(mutations, func() (interface{}, error) { return num(v), nil })
+		} else {
+			mutations = append(mutations, func() (interface{}, error) { return num(v + 1 + float64(g.rnd.Intn(span))), nil })
+		}
+	}
+
+	if v, ok := m["exclusiveMaximum"].(float64); ok {
+		mutations = append(mutations, func() (interface{}, error) { return num(v + float64(g.rnd.Intn(span))), nil })
+	}
+
+	if mult, ok := m["multipleOf"].(float64); ok && mult > 0 {
+		mutations = append(mutations, func() (interface{}, error) {
+			v := float64(g.rnd.Intn(20)-10)*mult + mult/2
+			if integer && v != float64(int64(v)) {
+				// half a multiple of an odd integer is not an integer, which breaks the type instead
+				return nil, ErrAnyValue
+			}
+			return num(v), nil
+		})
+	}
+
+	return mutations
+}
+
+func (g *Generator) stringMutations(m map[string]interface{}) []mutation {
+
+	var mutations []mutation
+
+	if v, ok := m["minLength"].(float64); ok && v > 0 {
+		mutations = append(mutations, func() (interface{}, error) { return g.randomString(0, int(v)-1), nil })
+	}
+
+	if v, ok := m["maxLength"].(float64); ok {
+		mutations = append(mutations, func() (interface{}, error) { return g.randomString(int(v)+1, int(v)+8), nil })
+	}
+
+	if pattern, ok := m["pattern"].(string); ok {
+		mutations = append(mutations, func() (interface{}, error) {
+			for i := 0; i < maxAttempts; i++ {
+				if s := g.randomString(0, 8+i); !matchesPattern(pattern, s) {
+					return s, nil
+				}
+			}
+			return nil, ErrAnyValue
+		})
+	}
+
+	return mutations
+}
+
+func (g *Generator) arrayMutations(m map[string]interface{}) []mutation {
+
+	var mutations []mutation
+
+	items := func(n int) (interface{}, error) {
+		arr := make([]interface{}, 0, n)
+
+		for i := 0; i < n; i++ {
+			item := m["items"]
+			if tuple, ok := item.([]interface{}); ok {
+				item = true
+				if i < len(tuple) {
+					item = tuple[i]
+				}
+			} else if item == nil {
+				item = true
+			}
+
+			v, err := g.valid(item)
+			if err != nil {
+				return nil, err
+			}
+
+			arr = append(arr, v)
+		}
+
+		return arr, nil
+	}
+
+	if v, ok := m["minItems"].(float64); ok && v > 0 {
+		mutations = append(mutations, func() (interface{}, error) { return items(int(v) - 1) })
+	}
+
+	if v, ok := m["maxItems"].(float64); ok {
+		mutations = append(mutations, func() (interface{}, error) { return items(int(v) + 1) })
+	}
+
+	if item, ok := m["items"].(map[string]interface{}); ok {
+		mutations = append(mutations, func() (interface{}, error) {
+			bad, err := g.invalid(item)
+			if err != nil {
+				return nil, err
+			}
+
+			v, err := g.array(m)
+			if err != nil {
+				return nil, err
+			}
+
+			arr := v.([]interface{})
+			if len(arr) == 0 {
+				return []interface{}{bad}, nil
+			}
+
+			arr[g.rnd.Intn(len(arr))] = bad
+
+			return arr, nil
+		})
+	}
+
+	if unique, _ := m["uniqueItems"].(bool); unique {
+		mutations = append(mutations, func() (interface{}, error) {
+			v, err := items(1)
+			if err != nil {
+				return nil, err
+			}
+
+			arr := v.([]interface{})
+
+			return append(arr, arr[0]), nil
+		})
+	}
+
+	return mutations
+}
+
+func (g *Generator) objectMutations(m map[string]interface{}) []mutation {
+
+	var mutations []mutation
+
+	object := func() (map[string]interface{}, error) {
+		v, err := g.object(m)
+		if err != nil {
+			return nil, err
+		}
+		return v.(map[string]interface{}), nil
+	}
+
+	if required := requiredOf(m); len(required) > 0 {
+		mutations = append(mutations, func() (interface{}, error) {
+			obj, err := object()
+			if err != nil {
+				return nil, err
+			}
+
+			delete(obj, required[g.rnd.Intn(len(required))])
+
+			return obj, nil
+		})
+	}
+
+	if props, ok := m["properties"].(map[string]interface{}); ok && len(props) > 0 {
+		mutations = append(mutations, func() (interface{}, error) {
+			names := make([]string, 0, len(props))
+			for name := range props {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			// properties are tried in random order, until one can be broken
+			for _, i := range g.rnd.Perm(len(names)) {
+				bad, err := g.invalid(props[names[i]])
+				if err == ErrAnyValue {
+					continue
+				}
+				if err != nil {
+					return nil, err
+				}
+
+				obj, err := object()
+				if err != nil {
+					return nil, err
+				}
+
+				obj[names[i]] = bad
+
+				return obj, nil
+			}
+
+			return nil, ErrAnyValue
+		})
+	}
+
+	// a property matching patternProperties would not be additional
+	if additional, ok := m["additionalProperties"].(bool); ok && !additional && m["patternProperties"] == nil {
+		mutations = append(mutations, func() (interface{}, error) {
+			obj, err := object()
+			if err != nil {
+				return nil, err
+			}
+
+			props, _ := m["properties"].(map[string]interface{})
+
+			for i := 0; ; i++ {
+				name := "unexpected" + strconv.Itoa(i)
+				if _, declared := props[name]; !declared {
+					obj[name] = g.any()
+					return obj, nil
+				}
+			}
+		})
+	}
+
+	return mutations
+}
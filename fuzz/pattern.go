@@ -0,0 +1,101 @@
+package fuzz
+
+import (
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+// maxRepeat bounds the repetitions of *, + and {n,}
+const maxRepeat = 5
+
+// printable is used for ., negated classes and strings that are not constrained by a pattern
+var printable = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 _-.:/")
+
+// matching returns a random string matching a regular expression
+func matching(rnd *rand.Rand, pattern string) (string, error) {
+
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+
+	writeRegexp(rnd, &sb, re.Simplify())
+
+	return sb.String(), nil
+}
+
+func writeRegexp(rnd *rand.Rand, sb *strings.Builder, re *syntax.Regexp) {
+
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && rnd.Intn(2) == 0 {
+				r = unicode.SimpleFold(r)
+			}
+			sb.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		sb.WriteRune(classRune(rnd, re.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		sb.WriteRune(printable[rnd.Intn(len(printable))])
+	case syntax.OpCapture:
+		writeRegexp(rnd, sb, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writeRegexp(rnd, sb, sub)
+		}
+	case syntax.OpAlternate:
+		writeRegexp(rnd, sb, re.Sub[rnd.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, maxRepeat
+		case syntax.OpPlus:
+			min, max = 1, maxRepeat
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + maxRepeat
+		}
+		for i, n := 0, min+rnd.Intn(max-min+1); i < n; i++ {
+			writeRegexp(rnd, sb, re.Sub[0])
+		}
+	}
+	// anchors, word boundaries and empty matches write nothing
+}
+
+// classRune picks a rune of a character class, given as pairs of inclusive ranges; printable runes are preferred,
+// so that negated classes such as [^"] produce readable strings
+func classRune(rnd *rand.Rand, ranges []rune) rune {
+
+	in := func(r rune) bool {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if ranges[i] <= r && r <= ranges[i+1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < 10; i++ {
+		if r := printable[rnd.Intn(len(printable))]; in(r) {
+			return r
+		}
+	}
+
+	i := rnd.Intn(len(ranges)/2) * 2
+	lo, hi := ranges[i], ranges[i+1]
+
+	// avoid the huge tail ranges of negated classes
+	if hi-lo > 0xff {
+		hi = lo + 0xff
+	}
+
+	return lo + rune(rnd.Int63n(int64(hi-lo+1)))
+}
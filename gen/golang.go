@@ -0,0 +1,492 @@
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	"github.com/octanolabs/g0penrpc/internal/docutil"
+	"github.com/octanolabs/g0penrpc/internal/jsonrpc"
+)
+
+// GoOptions configures the generated Go source
+type GoOptions struct {
+	// Package is the name of the generated package, "api" if empty
+	Package string
+	// Service is the name of the generated interface, "Service" if empty
+	Service string
+}
+
+// Go generates a Go source file from a document, declaring:
+// a type for every schema in components/schemas, a params struct and a result type for every method,
+// a service interface with one method per openrpc method and a dispatcher binding the interface to a JSON-RPC 2.0 server
+func Go(doc *openrpc.DocumentSpec1, opts GoOptions) ([]byte, error) {
+
+	if opts.Package == "" {
+		opts.Package = "api"
+	}
+
+	if opts.Service == "" {
+		opts.Service = "Service"
+	}
+
+	res, err := openrpc.NewRefResolver(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &goGenerator{
+		res:  res,
+		ns:   namespace{opts.Service: true, opts.Service + "Dispatcher": true, "New" + opts.Service + "Dispatcher": true, "Error": true},
+		refs: map[string]string{},
+	}
+
+	file := goFile{Package: opts.Package, Service: opts.Service, Envelope: jsonrpc.Source}
+
+	for _, e := range openrpc.StandardErrors() {
+		file.StandardErrors = append(file.StandardErrors, goError{Name: "err" + openrpc.ErrorName(e), Code: e.Code, Message: e.Message})
+	}
+
+	schemas := docutil.ComponentSchemas(res.Root())
+
+	names := sortedKeys(schemas)
+	for _, name := range names {
+		g.refs[componentRef(name)] = g.ns.unique(exportedName(name))
+	}
+
+	for _, name := range names {
+		if err := g.declare(g.refs[componentRef(name)], schemas[name], false); err != nil {
+			return nil, errors.New("error generating schema " + name + ": " + err.Error())
+		}
+	}
+
+	methods, err := doc.ResolveMethods()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range methods {
+		gm, err := g.method(m)
+		if err != nil {
+			return nil, errors.New("error generating method " + m.Name + ": " + err.Error())
+		}
+		file.Methods = append(file.Methods, gm)
+	}
+
+	file.Types = g.decls
+
+	var buf bytes.Buffer
+
+	if err := goTemplate.Execute(&buf, file); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, errors.New("generated invalid go source: " + err.Error())
+	}
+
+	return src, nil
+}
+
+type goFile struct {
+	Package        string
+	Service        string
+	Types          []string
+	Methods        []goMethod
+	StandardErrors []goError
+	// Envelope is the JSON-RPC envelope shared with the mock server: the Error type, requests and responses
+	Envelope string
+}
+
+// goError is an error predefined by JSON-RPC 2.0, declared as an unexported variable of the generated package
+type goError struct {
+	Name    string
+	Code    int
+	Message string
+}
+
+type goMethod struct {
+	RPCName    string
+	Name       string
+	Doc        string
+	Params     string
+	Result     string
+	Fields     []goField
+	Deprecated bool
+}
+
+type goField struct {
+	JSON     string
+	Required bool
+}
+
+type goGenerator struct {
+	res   *openrpc.RefResolver
+	ns    namespace
+	refs  map[string]string
+	decls []string
+}
+
+func (g *goGenerator) method(m *openrpc.Method) (goMethod, error) {
+
+	name := exportedName(m.Name)
+	gm := goMethod{
+		RPCName:    m.Name,
+		Name:       name,
+		Params:     g.ns.unique(name + "Params"),
+		Result:     g.ns.unique(name + "Result"),
+		Deprecated: m.Deprecated,
+	}
+
+	gm.Doc = m.Summary
+	if gm.Doc == "" {
+		gm.Doc = m.Description
+	}
+
+	fieldNames := namespace{}
+
+	var fields strings.Builder
+
+	for _, p := range m.Params {
+		sch, err := g.res.Schema(p)
+		if err != nil {
+			return gm, err
+		}
+
+		field := fieldNames.unique(exportedName(p.Name))
+
+		typ, err := g.goType(sch, gm.Params+field)
+		if err != nil {
+			return gm, errors.New("param " + p.Name + ": " + err.Error())
+		}
+
+		tag := p.Name
+		if !p.Required {
+			tag += ",omitempty"
+		}
+
+		doc := p.Summary
+		if doc == "" {
+			doc = p.Description
+		}
+
+		fmt.Fprintf(&fields, "%s%s %s `json:\"%s\"`\n", comment("\t", doc), field, typ, tag)
+
+		gm.Fields = append(gm.Fields, goField{JSON: p.Name, Required: p.Required})
+	}
+
+	g.decls = append(g.decls, fmt.Sprintf("%stype %s struct {\n%s}", comment("", gm.Params+" are the params of "+m.Name), gm.Params, fields.String()))
+
+	if m.Result == nil {
+		g.decls = append(g.decls, fmt.Sprintf("type %s = interface{}", gm.Result))
+		return gm, nil
+	}
+
+	sch, err := g.res.Schema(m.Result)
+	if err != nil {
+		return gm, err
+	}
+
+	if err := g.declare(gm.Result, sch, true); err != nil {
+		return gm, errors.New("result: " + err.Error())
+	}
+
+	return gm, nil
+}
+
+// declare adds a type declaration for the schema;
+// if alias is true, schemas that are not inline objects are declared as aliases
+func (g *goGenerator) declare(name string, sch interface{}, alias bool) error {
+
+	if m, ok := sch.(map[string]interface{}); ok {
+		if typ, _ := schemaType(m); typ == "object" && m["properties"] != nil {
+			body, err := g.structType(m, name)
+			if err != nil {
+				return err
+			}
+
+			g.decls = append(g.decls, fmt.Sprintf("%stype %s %s", comment("", docutil.Description(m)), name, body))
+
+			return nil
+		}
+	}
+
+	typ, err := g.goType(sch, name+"Value")
+	if err != nil {
+		return err
+	}
+
+	op := " "
+	if alias {
+		op = " = "
+	}
+
+	g.decls = append(g.decls, fmt.Sprintf("%stype %s%s%s", comment("", docutil.Description(sch)), name, op, typ))
+
+	return nil
+}
+
+// goType returns the Go type of a schema; inline objects are declared as named structs, using hint as their name
+func (g *goGenerator) goType(sch interface{}, hint string) (string, error) {
+
+	m, ok := sch.(map[string]interface{})
+	if !ok {
+		return "interface{}", nil
+	}
+
+	if ref, ok := openrpc.RefOf(m); ok {
+		if name, ok := g.refs[refKey(ref)]; ok {
+			return name, nil
+		}
+
+		target, err := g.res.Resolve(ref)
+		if err != nil {
+			return "", err
+		}
+
+		return g.goType(target, hint)
+	}
+
+	typ, nullable := schemaType(m)
+
+	var t string
+
+	switch typ {
+	case "string":
+		t = "string"
+	case "integer":
+		t = "int64"
+	case "number":
+		t = "float64"
+	case "boolean":
+		t = "bool"
+	case "array":
+		if _, tuple := m["items"].([]interface{}); tuple || m["items"] == nil {
+			return "[]interface{}", nil
+		}
+
+		item, err := g.goType(m["items"], hint+"Item")
+		if err != nil {
+			return "", err
+		}
+
+		return "[]" + item, nil
+	case "object":
+		if m["properties"] != nil {
+			name := g.ns.unique(hint)
+
+			body, err := g.structType(m, name)
+			if err != nil {
+				return "", err
+			}
+
+			g.decls = append(g.decls, fmt.Sprintf("%stype %s %s", comment("", docutil.Description(m)), name, body))
+
+			t = name
+			break
+		}
+
+		value := m["additionalProperties"]
+
+		if patterns, ok := m["patternProperties"].(map[string]interface{}); ok && len(patterns) == 1 {
+			for _, v := range patterns {
+				value = v
+			}
+		}
+
+		elem, err := g.goType(value, hint+"Value")
+		if err != nil {
+			return "", err
+		}
+
+		return "map[string]" + elem, nil
+	default:
+		for _, key := range []string{"oneOf", "anyOf", "allOf"} {
+			if _, ok := m[key]; ok {
+				return "json.RawMessage", nil
+			}
+		}
+
+		return "interface{}", nil
+	}
+
+	if nullable {
+		t = "*" + t
+	}
+
+	return t, nil
+}
+
+func (g *goGenerator) structType(sch map[string]interface{}, name string) (string, error) {
+
+	props, _ := sch["properties"].(map[string]interface{})
+	required := requiredSet(sch)
+	fieldNames := namespace{}
+
+	var b strings.Builder
+
+	b.WriteString("struct {\n")
+
+	for _, prop := range sortedKeys(props) {
+		field := fieldNames.unique(exportedName(prop))
+
+		typ, err := g.goType(props[prop], name+field)
+		if err != nil {
+			return "", errors.New("property " + prop + ": " + err.Error())
+		}
+
+		tag := prop
+		if !required[prop] {
+			tag += ",omitempty"
+		}
+
+		fmt.Fprintf(&b, "%s%s %s `json:\"%s\"`\n", comment("\t", docutil.Description(props[prop])), field, typ, tag)
+	}
+
+	b.WriteString("}")
+
+	return b.String(), nil
+}
+
+var goTemplate = template.Must(template.New("go").Parse(`// Code generated by g0penrpc. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+{{range .Types}}{{.}}
+
+{{end}}
+// {{.Service}} is implemented by the server side of the API
+type {{.Service}} interface {
+{{- range .Methods}}
+	// {{.Name}} handles {{.RPCName}}{{if .Doc}}: {{.Doc}}{{end}}
+	{{- if .Deprecated}}
+	//
+	// Deprecated: {{.RPCName}} is deprecated
+	{{- end}}
+	{{.Name}}(ctx context.Context, params {{.Params}}) ({{.Result}}, error)
+{{- end}}
+}
+
+// The errors predefined by the JSON-RPC 2.0 specification
+var (
+{{- range .StandardErrors}}
+	{{.Name}} = Error{Code: {{.Code}}, Message: {{printf "%q" .Message}}}
+{{- end}}
+)
+
+// {{.Service}}Dispatcher binds a {{.Service}} to JSON-RPC 2.0 requests
+type {{.Service}}Dispatcher struct {
+	service {{.Service}}
+
+	// MapError converts the errors returned by the service into JSON-RPC errors, e.g. with the Fields method of an
+	// openrpc.ErrorCatalog; when it is nil or returns nil, *Error values are sent as they are and other errors
+	// become internal errors
+	MapError func(error) *Error
+}
+
+// New{{.Service}}Dispatcher returns a dispatcher calling service
+func New{{.Service}}Dispatcher(service {{.Service}}) *{{.Service}}Dispatcher {
+	return &{{.Service}}Dispatcher{service: service}
+}
+
+// Dispatch decodes params and calls the service method bound to method
+func (d *{{.Service}}Dispatcher) Dispatch(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+{{- range .Methods}}
+	case {{printf "%q" .RPCName}}:
+		var p {{.Params}}
+		if err := decodeParams(params, &p, []paramField{ {{- range .Fields}}{ {{- printf "%q" .JSON}}, {{.Required -}} },{{end -}} }); err != nil {
+			return nil, err
+		}
+		res, err := d.service.{{.Name}}(ctx, p)
+		if err != nil {
+			return nil, err
+		}
+		return res, nil
+{{- end}}
+	default:
+		return nil, newError(errMethodNotFound, "")
+	}
+}
+
+// ServeHTTP serves single and batch JSON-RPC requests
+func (d *{{.Service}}Dispatcher) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	serveHTTP(w, r, func(ctx context.Context, method string, params json.RawMessage) (interface{}, *Error) {
+		res, err := d.Dispatch(ctx, method, params)
+		if err != nil {
+			return nil, d.mapError(err)
+		}
+		return res, nil
+	})
+}
+
+func (d *{{.Service}}Dispatcher) mapError(err error) *Error {
+	if d.MapError != nil {
+		if e := d.MapError(err); e != nil {
+			return e
+		}
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+
+	return newError(errInternalError, "")
+}
+
+type paramField struct {
+	name     string
+	required bool
+}
+
+// decodeParams decodes by-position and by-name params into dst
+func decodeParams(raw json.RawMessage, dst interface{}, fields []paramField) error {
+	named := map[string]json.RawMessage{}
+
+	if len(raw) > 0 && string(raw) != "null" {
+		var positional []json.RawMessage
+
+		if err := json.Unmarshal(raw, &positional); err == nil {
+			if len(positional) > len(fields) {
+				return newError(errInvalidParams, "too many params")
+			}
+			for i, p := range positional {
+				named[fields[i].name] = p
+			}
+		} else if err := json.Unmarshal(raw, &named); err != nil {
+			return newError(errInvalidParams, err.Error())
+		}
+	}
+
+	for _, f := range fields {
+		if _, ok := named[f.name]; f.required && !ok {
+			return newError(errInvalidParams, "missing "+f.name)
+		}
+	}
+
+	data, err := json.Marshal(named)
+	if err != nil {
+		return newError(errInvalidParams, err.Error())
+	}
+
+	if err := json.Unmarshal(data, dst); err != nil {
+		return newError(errInvalidParams, err.Error())
+	}
+
+	return nil
+}
+
+{{.Envelope}}`))
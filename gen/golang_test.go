@@ -0,0 +1,193 @@
+package gen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	"github.com/octanolabs/g0penrpc/internal/testutil"
+)
+
+func TestGo(t *testing.T) {
+
+	doc := testutil.Document(t, "petstore.json")
+
+	src, err := Go(doc, GoOptions{Package: "petstore"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+
+	file, err := parser.ParseFile(fset, "petstore.go", src, 0)
+	if err != nil {
+		t.Fatalf("error parsing generated source: %v\n%s", err, src)
+	}
+
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+
+	pkg, err := conf.Check("petstore", fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("error type checking generated source: %v\n%s", err, src)
+	}
+
+	t.Run("service", func(t *testing.T) {
+
+		iface, ok := pkg.Scope().Lookup("Service").Type().Underlying().(*types.Interface)
+		if !ok {
+			t.Fatalf("Service is not an interface")
+		}
+
+		want := map[string]string{
+			"ListPets":   "func(ctx context.Context, params petstore.ListPetsParams) (petstore.ListPetsResult, error)",
+			"CreatePet":  "func(ctx context.Context, params petstore.CreatePetParams) (petstore.CreatePetResult, error)",
+			"GetPet":     "func(ctx context.Context, params petstore.GetPetParams) (petstore.GetPetResult, error)",
+			"FamilyTree": "func(ctx context.Context, params petstore.FamilyTreeParams) (petstore.FamilyTreeResult, error)",
+			"Ping":       "func(ctx context.Context, params petstore.PingParams) (petstore.PingResult, error)",
+		}
+
+		if iface.NumMethods() != len(want) {
+			t.Errorf("error, got %v methods instead of %v", iface.NumMethods(), len(want))
+		}
+
+		for i := 0; i < iface.NumMethods(); i++ {
+			m := iface.Method(i)
+			if got := m.Type().String(); got != want[m.Name()] {
+				t.Errorf("error, method %v has signature %v instead of %v", m.Name(), got, want[m.Name()])
+			}
+		}
+	})
+
+	t.Run("types", func(t *testing.T) {
+
+		want := map[string]string{
+			"Pet":             "struct{Born string \"json:\\\"born,omitempty\\\"\"; Id petstore.PetId \"json:\\\"id\\\"\"; Location petstore.PetLocation \"json:\\\"location,omitempty\\\"\"; Name string \"json:\\\"name\\\"\"; Tag *string \"json:\\\"tag,omitempty\\\"\"}",
+			"PetId":           "int64",
+			"ListPetsResult":  "[]petstore.Pet",
+			"CreatePetParams": "struct{Name string \"json:\\\"name\\\"\"; Kind string \"json:\\\"kind,omitempty\\\"\"}",
+			"GetPetResult":    "struct{Owners map[string]string \"json:\\\"owners,omitempty\\\"\"; Pet petstore.Pet \"json:\\\"pet,omitempty\\\"\"}",
+		}
+
+		for name, typ := range want {
+			obj := pkg.Scope().Lookup(name)
+			if obj == nil {
+				t.Errorf("error, %v is not declared", name)
+				continue
+			}
+
+			if got := obj.Type().Underlying().String(); got != typ {
+				t.Errorf("error, %v is %v instead of %v", name, got, typ)
+			}
+		}
+	})
+
+	t.Run("deprecated", func(t *testing.T) {
+
+		if !strings.Contains(string(src), "Deprecated: get_pet is deprecated") {
+			t.Errorf("error, deprecated method is not marked")
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+
+		// gofmt aligns the declarations
+		flat := strings.Join(strings.Fields(string(src)), " ")
+
+		for _, e := range openrpc.StandardErrors() {
+			decl := fmt.Sprintf("err%s = Error{Code: %d, Message: %q}", openrpc.ErrorName(e), e.Code, e.Message)
+			if !strings.Contains(flat, decl) {
+				t.Errorf("error, %s is not declared", decl)
+			}
+		}
+	})
+}
+
+func TestExportedName(t *testing.T) {
+
+	tests := map[string]string{
+		"eth_getBlockByNumber": "EthGetBlockByNumber",
+		"types.Block":          "TypesBlock",
+		"types.Block[]":        "TypesBlockSlice",
+		"Object[anything]":     "ObjectAnything",
+		"2fa":                  "X2fa",
+		"":                     "X",
+	}
+
+	for name, want := range tests {
+		if got := exportedName(name); got != want {
+			t.Errorf("error, got %v instead of %v for %v", got, want, name)
+		}
+	}
+}
+
+// TestDispatcherErrorCatalog runs testdata/dispatcher against the generated dispatcher of the petstore, in a module
+// requiring this one, so that an openrpc.ErrorCatalog translates the errors of the service on the wire
+func TestDispatcherErrorCatalog(t *testing.T) {
+
+	if testing.Short() {
+		t.Skip("builds a module with the go command")
+	}
+
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("the go command is not available")
+	}
+
+	src, err := Go(testutil.Document(t, "petstore.json"), GoOptions{Package: "petstore"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "dispatcher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	mod := "module example.com/petstore\n\ngo 1.14\n\nrequire github.com/octanolabs/g0penrpc v0.0.0\n\n" +
+		"replace github.com/octanolabs/g0penrpc => " + root + "\n"
+
+	sum, err := ioutil.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	test, err := ioutil.ReadFile("testdata/dispatcher/catalog_test.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string][]byte{
+		"go.mod":          []byte(mod),
+		"go.sum":          sum,
+		"petstore.go":     src,
+		"catalog_test.go": test,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(goCmd, "test", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off")
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("error, testing the generated dispatcher: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,56 @@
+package gen
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// exportedName turns method, param and component names (eth_getBlock, types.Block[], Object[anything])
+// into exported Go identifiers
+func exportedName(name string) string {
+
+	name = strings.Replace(name, "[]", " Slice ", -1)
+
+	var (
+		b     strings.Builder
+		upper = true
+	)
+
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			b.WriteRune(r)
+		default:
+			upper = true
+		}
+	}
+
+	id := b.String()
+
+	if id == "" || unicode.IsDigit([]rune(id)[0]) {
+		id = "X" + id
+	}
+
+	return id
+}
+
+// namespace hands out unique identifiers
+type namespace map[string]bool
+
+func (ns namespace) unique(name string) string {
+
+	id := name
+
+	for i := 2; ns[id]; i++ {
+		id = name + strconv.Itoa(i)
+	}
+
+	ns[id] = true
+
+	return id
+}
@@ -0,0 +1,111 @@
+package gen
+
+import (
+	"sort"
+	"strings"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+// Helpers to inspect decoded json schemas
+
+// componentRef returns the key of a component schema in the refs of a generator
+func componentRef(name string) string {
+	name = strings.Replace(name, "~", "~0", -1)
+	name = strings.Replace(name, "/", "~1", -1)
+
+	return "/components/schemas/" + name
+}
+
+// refKey returns the key of a reference in the refs of a generator, which is the json pointer it holds,
+// so that percent-encoded and plain forms of the same reference match
+func refKey(ref string) string {
+
+	ptr, err := openrpc.NewPointer(ref)
+	if err != nil {
+		return ref
+	}
+
+	return ptr.String()
+}
+
+// schemaType returns the first non null type of a schema and whether null is allowed
+func schemaType(sch map[string]interface{}) (typ string, nullable bool) {
+
+	switch t := sch["type"].(type) {
+	case string:
+		typ = t
+	case []interface{}:
+		for _, item := range t {
+			s, _ := item.(string)
+			if s == "null" {
+				nullable = true
+			} else if typ == "" {
+				typ = s
+			}
+		}
+	}
+
+	if typ == "null" {
+		return "", true
+	}
+
+	if typ == "" {
+		if _, ok := sch["properties"]; ok {
+			typ = "object"
+		} else if _, ok := sch["items"]; ok {
+			typ = "array"
+		} else if enum, ok := sch["enum"].([]interface{}); ok && len(enum) > 0 {
+			switch enum[0].(type) {
+			case string:
+				typ = "string"
+			case float64:
+				typ = "number"
+			case bool:
+				typ = "boolean"
+			}
+		}
+	}
+
+	return typ, nullable
+}
+
+func requiredSet(sch map[string]interface{}) map[string]bool {
+
+	set := map[string]bool{}
+
+	list, _ := sch["required"].([]interface{})
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			set[s] = true
+		}
+	}
+
+	return set
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// comment formats text as a line comment, returning an empty string if text is empty
+func comment(indent, text string) string {
+
+	if text == "" {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, l := range lines {
+		lines[i] = indent + "// " + strings.TrimSpace(l)
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,103 @@
+package petstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+var errPetNotFound = errors.New("pet not found")
+
+type service struct{}
+
+func (service) ListPets(ctx context.Context, params ListPetsParams) (ListPetsResult, error) {
+	return nil, errors.New("database is down")
+}
+
+func (service) CreatePet(ctx context.Context, params CreatePetParams) (CreatePetResult, error) {
+	var res CreatePetResult
+	return res, &Error{Code: 7, Message: "as is"}
+}
+
+func (service) GetPet(ctx context.Context, params GetPetParams) (GetPetResult, error) {
+	var res GetPetResult
+	return res, fmt.Errorf("loading pet %v: %w", params.PetId, errPetNotFound)
+}
+
+func (service) FamilyTree(ctx context.Context, params FamilyTreeParams) (FamilyTreeResult, error) {
+	var res FamilyTreeResult
+	return res, nil
+}
+
+func (service) Ping(ctx context.Context, params PingParams) (PingResult, error) {
+	var res PingResult
+	return res, nil
+}
+
+func TestErrorCatalog(t *testing.T) {
+
+	catalog := openrpc.NewErrorCatalog(nil)
+
+	if err := catalog.Register(1001, "Pet not found", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := catalog.Map(errPetNotFound, 1001); err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewServiceDispatcher(service{})
+	d.MapError = func(err error) *Error {
+		if code, message, data, ok := catalog.Fields(err); ok {
+			return &Error{Code: code, Message: message, Data: data}
+		}
+		return nil
+	}
+
+	srv := httptest.NewServer(d)
+	defer srv.Close()
+
+	body := `[
+		{"jsonrpc": "2.0", "id": 1, "method": "get_pet", "params": {"petId": 3}},
+		{"jsonrpc": "2.0", "id": 2, "method": "list_pets", "params": []},
+		{"jsonrpc": "2.0", "id": 3, "method": "create_pet", "params": {"name": "Fido"}}
+	]`
+
+	res, err := http.Post(srv.URL, "application/json", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var responses []struct {
+		ID    int
+		Error *Error
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&responses); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[int]Error{
+		1: {Code: 1001, Message: "Pet not found"},
+		2: {Code: -32603, Message: "Internal error"},
+		3: {Code: 7, Message: "as is"},
+	}
+
+	if len(responses) != len(expected) {
+		t.Fatalf("error, got %d responses instead of %d", len(responses), len(expected))
+	}
+
+	for _, r := range responses {
+		if r.Error == nil || *r.Error != expected[r.ID] {
+			t.Errorf("error, request %d: expected %v, got %v", r.ID, expected[r.ID], r.Error)
+		}
+	}
+}
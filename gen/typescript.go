@@ -0,0 +1,284 @@
+package gen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	"github.com/octanolabs/g0penrpc/internal/docutil"
+	"github.com/octanolabs/g0penrpc/internal/jsonutil"
+)
+
+// TypeScript generates TypeScript declarations from a document: a type for every schema in components/schemas,
+// params and result types for every method, and a Methods interface mapping method names to them
+func TypeScript(doc *openrpc.DocumentSpec1) ([]byte, error) {
+
+	res, err := openrpc.NewRefResolver(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &tsGenerator{res: res, ns: namespace{"Methods": true}, refs: map[string]string{}}
+
+	var b bytes.Buffer
+
+	b.WriteString("// Code generated by g0penrpc. DO NOT EDIT.\n")
+
+	schemas := docutil.ComponentSchemas(res.Root())
+
+	names := sortedKeys(schemas)
+	for _, name := range names {
+		g.refs[componentRef(name)] = g.ns.unique(exportedName(name))
+	}
+
+	for _, name := range names {
+		typ, err := g.tsType(schemas[name], "")
+		if err != nil {
+			return nil, errors.New("error generating schema " + name + ": " + err.Error())
+		}
+
+		fmt.Fprintf(&b, "\n%sexport type %s = %s;\n", jsDoc("", docutil.Description(schemas[name]), false), g.refs[componentRef(name)], typ)
+	}
+
+	resolved, err := doc.ResolveMethods()
+	if err != nil {
+		return nil, err
+	}
+
+	var methods strings.Builder
+
+	for _, m := range resolved {
+		name := exportedName(m.Name)
+		params, result := g.ns.unique(name+"Params"), g.ns.unique(name+"Result")
+
+		var fields strings.Builder
+
+		for _, p := range m.Params {
+			sch, err := res.Schema(p)
+			if err != nil {
+				return nil, errors.New("error generating method " + m.Name + ": " + err.Error())
+			}
+
+			typ, err := g.tsType(sch, "  ")
+			if err != nil {
+				return nil, errors.New("error generating method " + m.Name + " param " + p.Name + ": " + err.Error())
+			}
+
+			doc := p.Summary
+			if doc == "" {
+				doc = p.Description
+			}
+
+			fmt.Fprintf(&fields, "%s  %s: %s;\n", jsDoc("  ", doc, p.Deprecated), propertyName(p.Name, p.Required), typ)
+		}
+
+		fmt.Fprintf(&b, "\n/** Params of %s */\nexport interface %s {\n%s}\n", m.Name, params, fields.String())
+
+		typ := "unknown"
+
+		if m.Result != nil {
+			sch, err := res.Schema(m.Result)
+			if err != nil {
+				return nil, errors.New("error generating method " + m.Name + ": " + err.Error())
+			}
+
+			if typ, err = g.tsType(sch, ""); err != nil {
+				return nil, errors.New("error generating method " + m.Name + " result: " + err.Error())
+			}
+		}
+
+		fmt.Fprintf(&b, "\n/** Result of %s */\nexport type %s = %s;\n", m.Name, result, typ)
+
+		doc := m.Summary
+		if doc == "" {
+			doc = m.Description
+		}
+
+		fmt.Fprintf(&methods, "%s  %s: { params: %s; result: %s };\n", jsDoc("  ", doc, m.Deprecated), quoteProperty(m.Name), params, result)
+	}
+
+	fmt.Fprintf(&b, "\n/** Methods maps the name of every method to its params and result types */\nexport interface Methods {\n%s}\n", methods.String())
+
+	return b.Bytes(), nil
+}
+
+type tsGenerator struct {
+	res  *openrpc.RefResolver
+	ns   namespace
+	refs map[string]string
+}
+
+// tsType returns the TypeScript type of a schema; indent is the indentation of the line the type is written on
+func (g *tsGenerator) tsType(sch interface{}, indent string) (string, error) {
+
+	m, ok := sch.(map[string]interface{})
+	if !ok {
+		return "unknown", nil
+	}
+
+	if ref, ok := openrpc.RefOf(m); ok {
+		if name, ok := g.refs[refKey(ref)]; ok {
+			return name, nil
+		}
+
+		target, err := g.res.Resolve(ref)
+		if err != nil {
+			return "", err
+		}
+
+		return g.tsType(target, indent)
+	}
+
+	if enum, ok := m["enum"].([]interface{}); ok && len(enum) > 0 {
+		values := make([]string, len(enum))
+		for i, v := range enum {
+			values[i] = jsonutil.String(v)
+		}
+		return strings.Join(values, " | "), nil
+	}
+
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alts, ok := m[key].([]interface{}); ok {
+			types := make([]string, len(alts))
+			for i, alt := range alts {
+				t, err := g.tsType(alt, indent)
+				if err != nil {
+					return "", err
+				}
+				types[i] = t
+			}
+			return strings.Join(types, " | "), nil
+		}
+	}
+
+	typ, nullable := schemaType(m)
+
+	var t string
+
+	switch typ {
+	case "string":
+		t = "string"
+	case "integer", "number":
+		t = "number"
+	case "boolean":
+		t = "boolean"
+	case "array":
+		item := "unknown"
+
+		if m["items"] != nil {
+			var err error
+			if item, err = g.tsType(m["items"], indent); err != nil {
+				return "", err
+			}
+		}
+
+		if strings.ContainsAny(item, " |{") {
+			item = "(" + item + ")"
+		}
+
+		t = item + "[]"
+	case "object":
+		if props, ok := m["properties"].(map[string]interface{}); ok {
+			required := requiredSet(m)
+
+			var b strings.Builder
+
+			b.WriteString("{\n")
+
+			for _, prop := range sortedKeys(props) {
+				pt, err := g.tsType(props[prop], indent+"  ")
+				if err != nil {
+					return "", errors.New("property " + prop + ": " + err.Error())
+				}
+
+				fmt.Fprintf(&b, "%s%s  %s: %s;\n", jsDoc(indent+"  ", docutil.Description(props[prop]), false), indent, propertyName(prop, required[prop]), pt)
+			}
+
+			b.WriteString(indent + "}")
+
+			t = b.String()
+			break
+		}
+
+		value := m["additionalProperties"]
+
+		if patterns, ok := m["patternProperties"].(map[string]interface{}); ok && len(patterns) == 1 {
+			for _, v := range patterns {
+				value = v
+			}
+		}
+
+		elem, err := g.tsType(value, indent)
+		if err != nil {
+			return "", err
+		}
+
+		t = "Record<string, " + elem + ">"
+	case "":
+		t = "unknown"
+	default:
+		t = "null"
+	}
+
+	if nullable {
+		t += " | null"
+	}
+
+	return t, nil
+}
+
+var jsIdentifier = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func quoteProperty(name string) string {
+
+	if jsIdentifier.MatchString(name) {
+		return name
+	}
+
+	return jsonutil.String(name)
+}
+
+func propertyName(name string, required bool) string {
+
+	name = quoteProperty(name)
+
+	if !required {
+		name += "?"
+	}
+
+	return name
+}
+
+func jsDoc(indent, text string, deprecated bool) string {
+
+	if deprecated {
+		if text != "" {
+			text += "\n"
+		}
+		text += "@deprecated"
+	}
+
+	if text == "" {
+		return ""
+	}
+
+	text = strings.Replace(text, "*/", "*\\/", -1)
+
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	if len(lines) == 1 {
+		return indent + "/** " + lines[0] + " */\n"
+	}
+
+	var b strings.Builder
+
+	b.WriteString(indent + "/**\n")
+	for _, l := range lines {
+		b.WriteString(indent + " * " + strings.TrimSpace(l) + "\n")
+	}
+	b.WriteString(indent + " */\n")
+
+	return b.String()
+}
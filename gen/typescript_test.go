@@ -0,0 +1,34 @@
+package gen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/octanolabs/g0penrpc/internal/testutil"
+)
+
+func TestTypeScript(t *testing.T) {
+
+	doc := testutil.Document(t, "petstore.json")
+
+	src, err := TypeScript(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"export type PetId = number;",
+		"  tag?: \"good\" | null;",
+		"export interface CreatePetParams {\n  name: string;\n  kind?: \"dog\" | \"cat\";\n}",
+		"export type Family = {\n  children?: Family[];\n  pet: Pet;\n};",
+		"export type ListPetsResult = Pet[];",
+		"  owners?: Record<string, string>;",
+		"  /** @deprecated */\n  get_pet: { params: GetPetParams; result: GetPetResult };",
+	}
+
+	for _, w := range want {
+		if !strings.Contains(string(src), w) {
+			t.Errorf("error, generated source does not contain %q:\n%s", w, src)
+		}
+	}
+}
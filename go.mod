@@ -5,4 +5,5 @@ go 1.14
 require (
 	github.com/qri-io/jsonpointer v0.1.1
 	github.com/qri-io/jsonschema v0.2.0
+	gopkg.in/yaml.v3 v3.0.1
 )
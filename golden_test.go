@@ -0,0 +1,104 @@
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata/golden")
+
+type goldenBlock struct {
+	Number uint64
+	Hash   string
+	Parent *goldenBlock
+	Txs    []goldenTx
+	Extra  map[string]interface{}
+}
+
+type goldenTx struct {
+	From  string
+	To    string
+	Value float64
+}
+
+type goldenAddress [20]byte
+
+var goldenCases = []struct {
+	name       string
+	types      []interface{}
+	exceptions []interface{}
+}{
+	{name: "empty"},
+	{name: "primitives", types: []interface{}{int64(0), float64(0), "", false}},
+	{name: "struct", types: []interface{}{goldenBlock{}}},
+	{name: "pointer", types: []interface{}{&goldenTx{}}},
+	{name: "slice", types: []interface{}{[]goldenTx{}}},
+	{name: "array", types: []interface{}{[4]int{}}},
+	{name: "map", types: []interface{}{map[string]goldenTx{}}},
+	{name: "exception", types: []interface{}{goldenAddress{}}, exceptions: []interface{}{goldenAddress{}}},
+}
+
+// TestGoldenRegistry locks down the components emitted for representative go types;
+// run go test -update to regenerate the golden files after an intended change
+func TestGoldenRegistry(t *testing.T) {
+
+	for _, c := range goldenCases {
+		t.Run(c.name, func(t *testing.T) {
+
+			root, _ := NewPointer("/components/schemas")
+
+			reg, err := NewSchemaRegistry(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, e := range c.exceptions {
+				reg.AddTypeException(reflect.TypeOf(e))
+			}
+
+			for _, typ := range c.types {
+				if _, _, err := reg.RegisterType(reflect.TypeOf(typ), false); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			data, err := reg.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var obj map[string]json.RawMessage
+			if err := json.Unmarshal(data, &obj); err != nil {
+				t.Fatalf("error, registry is not a json object: %v\n%s", err, data)
+			}
+
+			var got bytes.Buffer
+			if err := json.Indent(&got, data, "", "  "); err != nil {
+				t.Fatal(err)
+			}
+			got.WriteByte('\n')
+
+			path := filepath.Join("testdata", "golden", c.name+".json")
+
+			if *update {
+				if err := ioutil.WriteFile(path, got.Bytes(), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("error, registry does not match %v:\n%s", path, got.Bytes())
+			}
+		})
+	}
+}
@@ -2,6 +2,11 @@ package openrpc
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
 	jptr "github.com/qri-io/jsonpointer"
 	jsch "github.com/qri-io/jsonschema"
 )
@@ -10,7 +15,7 @@ import (
 type Pointer interface {
 	//Refs returns a slice containing all the (ordered) references of a pointer
 	Refs() []string
-	//String formats the references as a slash-separated string
+	//String formats the references as a slash-separated string, escaping '~' and '/' in references as in RFC 6901
 	String() string
 
 	json.Marshaler
@@ -28,16 +33,79 @@ func (jp *jsonPointer) String() string {
 	return jp.p.String()
 }
 
+// MarshalJSON writes the pointer as a reference object, using the uri fragment representation of the pointer
 func (jp *jsonPointer) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]string{"$ref": "#" + jp.String()})
+	return json.Marshal(map[string]string{"$ref": "#" + fragmentEscape(jp.String())})
 }
 
+// NewPointer parses either a json pointer (e.g. /components/schemas/a~1b) or its uri fragment
+// representation (e.g. #/components/schemas/a~1b%5B%5D)
 func NewPointer(path string) (Pointer, error) {
+
+	if strings.HasPrefix(path, "#") {
+		fragment, err := url.PathUnescape(path[1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pointer %s: %v", path, err)
+		}
+		path = fragment
+	}
+
+	if path != "" && path[0] != '/' {
+		return nil, errors.New("invalid pointer " + path + ": non-empty pointers must begin with /")
+	}
+
 	p, err := jptr.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsonPointer{p: p}, nil
+}
+
+// ExternalPointer is a Pointer into another document, e.g. ./common.json#/Foo; Refs and String
+// describe the pointer inside that document
+type ExternalPointer interface {
+	Pointer
+	// Document returns the uri of the document, as written in the reference
+	Document() string
+}
+
+type externalPointer struct {
+	jsonPointer
+	doc string
+}
 
-	return &jsonPointer{p: p}, err
+func (ep *externalPointer) Document() string {
+	return ep.doc
 }
 
+func (ep *externalPointer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]string{"$ref": ep.doc + "#" + fragmentEscape(ep.String())})
+}
+
+// ParseRef parses the value of a $ref: local references (#/components/schemas/Foo) return a Pointer,
+// references to other documents (./common.json#/Foo, file:///specs/common.json) an ExternalPointer
+func ParseRef(ref string) (Pointer, error) {
+
+	i := strings.Index(ref, "#")
+	if i == 0 || ref == "" {
+		return NewPointer(ref)
+	}
+
+	doc, fragment := ref, "#"
+	if i > 0 {
+		doc, fragment = ref[:i], ref[i:]
+	}
+
+	p, err := NewPointer(fragment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &externalPointer{jsonPointer: jsonPointer{p: p.Refs()}, doc: doc}, nil
+}
+
+// newPointerFromRefs returns a pointer made of unescaped references
 func newPointerFromRefs(refs []string) Pointer {
 
 	if refs == nil {
@@ -47,6 +115,46 @@ func newPointerFromRefs(refs []string) Pointer {
 	return &jsonPointer{p: refs}
 }
 
+// appendRefs returns a pointer to a descendant of ptr, refs are unescaped references
+func appendRefs(ptr Pointer, refs ...string) Pointer {
+
+	var parent []string
+	if ptr != nil {
+		parent = ptr.Refs()
+	}
+
+	return newPointerFromRefs(append(append([]string{}, parent...), refs...))
+}
+
+// fragmentEscape percent-encodes the characters of a json pointer that are not allowed in a uri fragment
+func fragmentEscape(path string) string {
+
+	var b strings.Builder
+
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+
+		if fragmentChar(c) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
+
+// fragmentChar reports whether c can appear unescaped in a uri fragment (RFC 3986, section 3.5)
+func fragmentChar(c byte) bool {
+
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+
+	return strings.IndexByte("-._~!$&'()*+,;=:@/?", c) >= 0
+}
+
 //Schema is a json schema
 type Schema interface {
 	json.Marshaler
@@ -55,8 +163,28 @@ type Schema interface {
 
 type jsonSchema struct {
 	jsch.Schema
+	// raw keeps the decoded json, since jsch.Schema does not marshal some keywords (e.g. additionalProperties) back
+	raw json.RawMessage
 }
 
 func NewSchema() Schema {
 	return &jsonSchema{Schema: jsch.Schema{}}
 }
+
+func (s *jsonSchema) UnmarshalJSON(data []byte) error {
+	if err := s.Schema.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	s.raw = append(json.RawMessage{}, data...)
+
+	return nil
+}
+
+func (s *jsonSchema) MarshalJSON() ([]byte, error) {
+	if s.raw != nil {
+		return s.raw, nil
+	}
+
+	return s.Schema.MarshalJSON()
+}
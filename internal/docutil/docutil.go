@@ -0,0 +1,71 @@
+// Package docutil holds helpers on openrpc documents shared by the packages of the module
+package docutil
+
+import openrpc "github.com/octanolabs/g0penrpc"
+
+// ComponentSchemas returns the decoded components/schemas section of a document
+func ComponentSchemas(root interface{}) map[string]interface{} {
+
+	doc, _ := root.(map[string]interface{})
+	components, _ := doc["components"].(map[string]interface{})
+	schemas, _ := components["schemas"].(map[string]interface{})
+
+	return schemas
+}
+
+// Description returns the description of a decoded schema, or its title if it has none
+func Description(sch interface{}) string {
+
+	m, _ := sch.(map[string]interface{})
+
+	if d, ok := m["description"].(string); ok && d != "" {
+		return d
+	}
+
+	t, _ := m["title"].(string)
+
+	return t
+}
+
+// ParamName returns the name of the param an example param stands for: its own name if the method takes params
+// by name, otherwise the name of the param at its position
+func ParamName(m *openrpc.Method, p *openrpc.Example, i int) string {
+
+	if cd := m.ExampleParam(p, i); cd != nil {
+		return cd.Name
+	}
+
+	return p.Name
+}
+
+// NamedParams returns the values of the params of an example pairing by param name
+func NamedParams(m *openrpc.Method, ex *openrpc.ExamplePairing) map[string]interface{} {
+
+	params := map[string]interface{}{}
+
+	for i, p := range ex.Params {
+		if p != nil {
+			params[ParamName(m, p, i)] = p.Value
+		}
+	}
+
+	return params
+}
+
+// ExampleParams returns the params of an example pairing as sent: an object if the method takes params by name,
+// an array otherwise
+func ExampleParams(m *openrpc.Method, ex *openrpc.ExamplePairing) interface{} {
+
+	if m.ParamStructure == "by-name" {
+		return NamedParams(m, ex)
+	}
+
+	params := make([]interface{}, len(ex.Params))
+	for i, p := range ex.Params {
+		if p != nil {
+			params[i] = p.Value
+		}
+	}
+
+	return params
+}
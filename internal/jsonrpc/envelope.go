@@ -0,0 +1,112 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+)
+
+// Error is a JSON-RPC error object; handlers can return it to control the error sent to clients
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// newError returns a copy of a predefined error, with detail appended to its message if it is not empty
+func newError(e Error, detail string) *Error {
+	if detail != "" {
+		e.Message += ": " + detail
+	}
+	return &e
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// serveHTTP serves single and batch JSON-RPC requests, calling call for each of them
+func serveHTTP(w http.ResponseWriter, r *http.Request, call func(ctx context.Context, method string, params json.RawMessage) (interface{}, *Error)) {
+	var raw json.RawMessage
+
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		writeJSON(w, response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: newError(errParseError, "")})
+		return
+	}
+
+	var batch []json.RawMessage
+
+	if err := json.Unmarshal(raw, &batch); err != nil {
+		if res := handle(r.Context(), raw, call); res != nil {
+			writeJSON(w, res)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if len(batch) == 0 {
+		writeJSON(w, response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: newError(errInvalidRequest, "")})
+		return
+	}
+
+	results := make([]*response, 0, len(batch))
+
+	for _, req := range batch {
+		if res := handle(r.Context(), req, call); res != nil {
+			results = append(results, res)
+		}
+	}
+
+	if len(results) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	writeJSON(w, results)
+}
+
+// handle serves a single request, returning nil for notifications
+func handle(ctx context.Context, raw json.RawMessage, call func(ctx context.Context, method string, params json.RawMessage) (interface{}, *Error)) *response {
+	var req request
+
+	if err := json.Unmarshal(raw, &req); err != nil || req.JSONRPC != "2.0" || req.Method == "" {
+		return &response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: newError(errInvalidRequest, "")}
+	}
+
+	res, rpcErr := call(ctx, req.Method, req.Params)
+
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	if rpcErr != nil {
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: rpcErr}
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		return &response{JSONRPC: "2.0", ID: req.ID, Error: newError(errInternalError, "")}
+	}
+
+	return &response{JSONRPC: "2.0", ID: req.ID, Result: data}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
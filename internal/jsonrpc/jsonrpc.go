@@ -0,0 +1,42 @@
+// Package jsonrpc implements the JSON-RPC 2.0 envelope of the mock server and of the dispatchers gen emits:
+// envelope.go is shared with generated code, which gets a copy of it from Source
+package jsonrpc
+
+//go:generate go run source_gen.go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+// The predefined errors envelope.go responds with, declared by gen in generated code
+var (
+	errParseError     = preset(openrpc.ParseError())
+	errInvalidRequest = preset(openrpc.InvalidRequest())
+	errInternalError  = preset(openrpc.InternalError())
+)
+
+func preset(e openrpc.Error) Error {
+	return Error{Code: e.Code, Message: e.Message}
+}
+
+// CallFunc returns the result of method for the json encoded params, or the error to respond with
+type CallFunc func(ctx context.Context, method string, params json.RawMessage) (interface{}, *Error)
+
+// ServeHTTP serves single and batch JSON-RPC requests, calling call for each of them
+func ServeHTTP(w http.ResponseWriter, r *http.Request, call CallFunc) {
+	serveHTTP(w, r, call)
+}
+
+// NewError returns the JSON-RPC error object of e
+func NewError(e *openrpc.Error) *Error {
+
+	if e == nil {
+		return nil
+	}
+
+	return &Error{Code: e.Code, Message: e.Message, Data: e.Data}
+}
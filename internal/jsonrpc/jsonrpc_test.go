@@ -0,0 +1,21 @@
+package jsonrpc
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+
+	data, err := ioutil.ReadFile("envelope.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	src := string(data)
+
+	if !strings.HasSuffix(src, Source) || !strings.Contains(src, "\n)\n\n"+Source) {
+		t.Error("error, Source is out of date with envelope.go, run go generate")
+	}
+}
@@ -0,0 +1,36 @@
+//go:build ignore
+// +build ignore
+
+// source_gen writes source.go, the Source constant holding the declarations of envelope.go
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"strconv"
+	"strings"
+)
+
+func main() {
+
+	data, err := ioutil.ReadFile("envelope.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src := string(data)
+
+	// the declarations follow the import block
+	i := strings.Index(src, "\n)\n")
+	if i < 0 {
+		log.Fatal("envelope.go has no import block")
+	}
+
+	out := "// Code generated by source_gen.go; DO NOT EDIT.\n\npackage jsonrpc\n\n" +
+		"// Source holds the declarations of envelope.go, for gen to copy into generated code\n" +
+		"const Source = " + strconv.Quote(strings.TrimSpace(src[i+3:])+"\n") + "\n"
+
+	if err := ioutil.WriteFile("source.go", []byte(out), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
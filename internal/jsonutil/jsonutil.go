@@ -0,0 +1,25 @@
+// Package jsonutil holds the json helpers shared by the packages of the module
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// String returns the compact json encoding of v, without escaping html characters, for messages and comments;
+// values that cannot be encoded are formatted with fmt
+func String(v interface{}) string {
+
+	var b bytes.Buffer
+
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(v); err != nil {
+		return fmt.Sprint(v)
+	}
+
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,36 @@
+// Package testutil loads the documents of the testdata directory of the module, for the tests of its packages
+package testutil
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+// Path returns the path of the file name in the testdata directory of the module
+func Path(name string) string {
+
+	_, file, _, _ := runtime.Caller(0)
+
+	return filepath.Join(filepath.Dir(file), "..", "..", "testdata", name)
+}
+
+// Document parses the document name of the testdata directory, e.g. petstore.json
+func Document(t testing.TB, name string) *openrpc.DocumentSpec1 {
+	t.Helper()
+
+	data, err := ioutil.ReadFile(Path(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := openrpc.ParseDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return doc
+}
@@ -0,0 +1,274 @@
+// Package lint checks the style and quality of openrpc documents, beyond their validity, with a configurable set of rules
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity tells how serious a problem is
+type Severity int
+
+const (
+	// Off disables a rule
+	Off Severity = iota
+	Warning
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Off:
+		return "off"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+// MarshalText writes a severity as off, warning or error
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText reads off, warning or error
+func (s *Severity) UnmarshalText(text []byte) error {
+
+	for _, sev := range []Severity{Off, Warning, Error} {
+		if string(text) == sev.String() {
+			*s = sev
+			return nil
+		}
+	}
+
+	return errors.New("unknown severity " + string(text) + ", expected off, warning or error")
+}
+
+// Problem is a finding of a rule; Path locates it in the document, e.g. methods/0/params/1
+type Problem struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Message  string   `json:"message"`
+}
+
+func (p Problem) String() string {
+	return p.Severity.String() + " " + p.Path + ": " + p.Message + " (" + p.Rule + ")"
+}
+
+// Document is the document being linted, along with a resolver of its references
+type Document struct {
+	*openrpc.DocumentSpec1
+	Resolver *openrpc.RefResolver
+}
+
+// ReportFunc reports a problem found by a rule at path
+type ReportFunc func(path, format string, args ...interface{})
+
+// Rule checks one aspect of documents
+type Rule struct {
+	// Name identifies the rule in configurations and reports, e.g. method-summary
+	Name        string
+	Description string
+	// Severity is the severity of the problems of the rule when the configuration does not set it
+	Severity Severity
+	Check    func(doc *Document, report ReportFunc)
+}
+
+// Config enables, disables and sets the severity of rules by name
+type Config struct {
+	Rules map[string]Severity `json:"rules" yaml:"rules"`
+}
+
+// ParseConfig decodes a json or yaml configuration, e.g.
+//
+//	rules:
+//	  method-summary: error
+//	  examples-exist: off
+func ParseConfig(data []byte) (*Config, error) {
+
+	c := &Config{}
+
+	// json is a subset of yaml
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Linter runs a set of rules over documents; it is safe for concurrent use
+type Linter struct {
+	mu     sync.RWMutex
+	rules  []Rule
+	config Config
+}
+
+// New returns a Linter with rules; use DefaultRules for the rules of this package
+func New(rules ...Rule) (*Linter, error) {
+
+	l := &Linter{}
+
+	for _, r := range rules {
+		if err := l.Register(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return l, nil
+}
+
+// Register adds a rule, failing if another rule has the same name
+func (l *Linter) Register(r Rule) error {
+
+	if r.Name == "" || r.Check == nil {
+		return errors.New("rule must have a name and a check")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, other := range l.rules {
+		if other.Name == r.Name {
+			return errors.New("rule " + r.Name + " is already registered")
+		}
+	}
+
+	l.rules = append(l.rules, r)
+
+	return nil
+}
+
+// Rules returns the registered rules, sorted by name
+func (l *Linter) Rules() []Rule {
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	rules := append([]Rule{}, l.rules...)
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Name < rules[j].Name })
+
+	return rules
+}
+
+// Configure applies a configuration, failing if it names a rule that is not registered
+func (l *Linter) Configure(c Config) error {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var unknown []string
+
+	for name := range c.Rules {
+		found := false
+		for _, r := range l.rules {
+			if r.Name == name {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return errors.New("unknown rules " + strings.Join(unknown, ", "))
+	}
+
+	l.config = c
+
+	return nil
+}
+
+// severity returns the severity of a rule under the configuration
+func (l *Linter) severity(r Rule) Severity {
+
+	if s, ok := l.config.Rules[r.Name]; ok {
+		return s
+	}
+
+	return r.Severity
+}
+
+// Lint runs the enabled rules over doc, returning their problems ordered by path and rule
+func (l *Linter) Lint(doc *openrpc.DocumentSpec1) ([]Problem, error) {
+
+	res, err := openrpc.NewRefResolver(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Document{DocumentSpec1: doc, Resolver: res}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	problems := []Problem{}
+
+	for _, r := range l.rules {
+		sev := l.severity(r)
+		if sev == Off {
+			continue
+		}
+
+		r.Check(d, func(path, format string, args ...interface{}) {
+			problems = append(problems, Problem{Rule: r.Name, Severity: sev, Path: path, Message: fmt.Sprintf(format, args...)})
+		})
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Path != problems[j].Path {
+			return pathLess(problems[i].Path, problems[j].Path)
+		}
+		return problems[i].Rule < problems[j].Rule
+	})
+
+	return problems, nil
+}
+
+// pathLess orders paths by segment, comparing indexes as numbers so that methods/2 comes before methods/10
+func pathLess(a, b string) bool {
+
+	as, bs := strings.Split(a, "/"), strings.Split(b, "/")
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if as[i] == bs[i] {
+			continue
+		}
+
+		ai, aErr := strconv.Atoi(as[i])
+		bi, bErr := strconv.Atoi(bs[i])
+
+		if aErr == nil && bErr == nil {
+			return ai < bi
+		}
+
+		return as[i] < bs[i]
+	}
+
+	return len(as) < len(bs)
+}
+
+// Failed reports whether any problem is an error, or a warning too if strict is true
+func Failed(problems []Problem, strict bool) bool {
+
+	for _, p := range problems {
+		if p.Severity == Error || (strict && p.Severity == Warning) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,239 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+const document = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "shop", "version": "1"},
+	"methods": [
+		{
+			"name": "get_item",
+			"summary": "returns an item",
+			"tags": [{"name": "items"}],
+			"params": [{"name": "itemId", "schema": {"type": "integer"}}],
+			"result": {"name": "item", "schema": {"$ref": "#/components/schemas/item"}},
+			"examples": [{"name": "first", "params": [{"name": "itemId", "value": 1}], "result": {"name": "item", "value": {}}}]
+		},
+		{
+			"name": "put_item",
+			"deprecated": true,
+			"tags": [{"name": "items"}, {"name": "admin"}],
+			"params": [{"name": "item_id", "schema": {"type": "integer"}}, {"name": "Value", "schema": {"type": "string"}}],
+			"result": {"name": "ok", "schema": {"$ref": "#/components/schemas/anything"}},
+			"errors": [
+				{"code": -32000, "message": "not found"},
+				{"$ref": "#/components/errors/notFound"}
+			]
+		}
+	],
+	"components": {
+		"schemas": {
+			"item": {"type": "object"},
+			"anything": {"description": "any value"}
+		},
+		"errors": {
+			"notFound": {"code": -32000, "message": "not found"}
+		},
+		"tags": {
+			"items": {"name": "items"}
+		}
+	}
+}`
+
+func parse(t *testing.T) *openrpc.DocumentSpec1 {
+
+	doc, err := openrpc.ParseDocument([]byte(document))
+	if err != nil {
+		t.Fatalf("error, parsing document: %v", err)
+	}
+
+	return doc
+}
+
+func lines(problems []Problem) []string {
+
+	var out []string
+	for _, p := range problems {
+		out = append(out, p.String())
+	}
+
+	return out
+}
+
+func TestDefaultRules(t *testing.T) {
+
+	l, err := New(DefaultRules()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := l.Lint(parse(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"warning methods/1: deprecated method put_item has no description (deprecated-description)",
+		"warning methods/1: method put_item has no examples (examples-exist)",
+		"warning methods/1: method put_item has no summary (method-summary)",
+		"error methods/1/errors/1: error code -32000 of method put_item is declared more than once (unique-error-codes)",
+		"warning methods/1/params/0: param item_id of method put_item is not camelCase (param-camel-case)",
+		"warning methods/1/params/1: param Value of method put_item is not camelCase (param-camel-case)",
+		"warning methods/1/result: result of method put_item accepts any value (no-any-result)",
+		"warning methods/1/tags/1: tag admin of method put_item is not declared (declared-tags)",
+	}
+
+	if got := lines(problems); strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("error, expected problems\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(got, "\n"))
+	}
+
+	if !Failed(problems, false) {
+		t.Errorf("error, expected an error to fail the document")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+
+	l, err := New(DefaultRules()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := ParseConfig([]byte("rules:\n  unique-error-codes: off\n  method-summary: error\n  param-camel-case: off\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.Configure(*c); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := l.Lint(parse(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[string]int{}
+	for _, p := range problems {
+		counts[p.Rule]++
+
+		if p.Rule == "method-summary" && p.Severity != Error {
+			t.Errorf("error, expected method-summary to be raised to error, got %s", p.Severity)
+		}
+	}
+
+	if counts["unique-error-codes"] != 0 || counts["param-camel-case"] != 0 {
+		t.Errorf("error, expected disabled rules not to report, got %v", counts)
+	}
+
+	if counts["method-summary"] != 1 || counts["examples-exist"] != 1 {
+		t.Errorf("error, expected enabled rules to report, got %v", counts)
+	}
+
+	c, err = ParseConfig([]byte(`{"rules": {"method-summary": "warning"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Rules["method-summary"] != Warning {
+		t.Errorf("error, expected json configuration to set warning, got %s", c.Rules["method-summary"])
+	}
+
+	if err := l.Configure(Config{Rules: map[string]Severity{"no-such-rule": Error}}); err == nil || !strings.Contains(err.Error(), "no-such-rule") {
+		t.Errorf("error, expected unknown rule to fail, got %v", err)
+	}
+
+	if _, err := ParseConfig([]byte("rules:\n  method-summary: fatal\n")); err == nil {
+		t.Errorf("error, expected unknown severity to fail")
+	}
+}
+
+func TestRegister(t *testing.T) {
+
+	custom := Rule{
+		Name:     "method-prefix",
+		Severity: Error,
+		Check: func(doc *Document, report ReportFunc) {
+			for i, m := range doc.Methods {
+				if !strings.HasPrefix(m.Name, "shop_") {
+					report("methods/"+strconv.Itoa(i), "method %s is not prefixed with shop_", m.Name)
+				}
+			}
+		},
+	}
+
+	l, err := New(custom)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := l.Register(custom); err == nil {
+		t.Errorf("error, expected registering a rule twice to fail")
+	}
+
+	problems, err := l.Lint(parse(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(problems) != 2 || problems[0].Rule != "method-prefix" || problems[1].Path != "methods/1" {
+		t.Errorf("error, unexpected problems %v", problems)
+	}
+}
+
+func TestPathLess(t *testing.T) {
+
+	if !pathLess("methods/2", "methods/10") || pathLess("methods/10/params/0", "methods/9") || !pathLess("methods/1", "methods/1/params/0") {
+		t.Errorf("error, expected indexes to be ordered as numbers")
+	}
+}
+
+func TestWrite(t *testing.T) {
+
+	problems := []Problem{
+		{Rule: "method-summary", Severity: Warning, Path: "methods/0", Message: "method a has no summary"},
+		{Rule: "unique-error-codes", Severity: Error, Path: "methods/1/errors/1", Message: "100% duplicate\ncode"},
+	}
+
+	var b bytes.Buffer
+
+	if err := WriteText(&b, problems); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(b.String(), "warning methods/0: method a has no summary (method-summary)\n") {
+		t.Errorf("error, unexpected text output\n%s", b.String())
+	}
+
+	b.Reset()
+
+	if err := WriteJSON(&b, problems); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []Problem
+	if err := json.Unmarshal(b.Bytes(), &decoded); err != nil || len(decoded) != 2 || decoded[1].Severity != Error {
+		t.Errorf("error, unexpected json output %s: %v", b.String(), err)
+	}
+
+	b.Reset()
+
+	if err := WriteGitHub(&b, "api/openrpc.json", problems); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "::warning file=api/openrpc.json,title=method-summary::methods/0: method a has no summary\n" +
+		"::error file=api/openrpc.json,title=unique-error-codes::methods/1/errors/1: 100%25 duplicate%0Acode\n"
+
+	if b.String() != expected {
+		t.Errorf("error, expected github output\n%s\ngot\n%s", expected, b.String())
+	}
+}
@@ -0,0 +1,58 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText writes a problem per line
+func WriteText(w io.Writer, problems []Problem) error {
+
+	for _, p := range problems {
+		if _, err := fmt.Fprintln(w, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON writes the problems as an indented json array
+func WriteJSON(w io.Writer, problems []Problem) error {
+
+	if problems == nil {
+		problems = []Problem{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(problems)
+}
+
+// WriteGitHub writes the problems as GitHub Actions workflow commands, which annotate file in pull requests
+func WriteGitHub(w io.Writer, file string, problems []Problem) error {
+
+	for _, p := range problems {
+		_, err := fmt.Fprintf(w, "::%s file=%s,title=%s::%s\n", p.Severity, escapeProperty(file), escapeProperty(p.Rule), escapeData(p.Path+": "+p.Message))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var dataEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+
+var propertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+
+func escapeData(s string) string {
+	return dataEscaper.Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return propertyEscaper.Replace(s)
+}
@@ -0,0 +1,191 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+// DefaultRules returns the rules of this package
+func DefaultRules() []Rule {
+	return []Rule{
+		MethodSummary,
+		ParamCamelCase,
+		NoAnyResult,
+		DeprecatedDescription,
+		DeclaredTags,
+		UniqueErrorCodes,
+		ExamplesExist,
+	}
+}
+
+// MethodSummary requires every method to have a summary
+var MethodSummary = Rule{
+	Name:        "method-summary",
+	Description: "every method has a summary",
+	Severity:    Warning,
+	Check: func(doc *Document, report ReportFunc) {
+		eachMethod(doc, func(path string, m *openrpc.Method) {
+			if m.Summary == "" {
+				report(path, "method %s has no summary", m.Name)
+			}
+		})
+	},
+}
+
+var camelCase = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+
+// ParamCamelCase requires param names to be camelCase
+var ParamCamelCase = Rule{
+	Name:        "param-camel-case",
+	Description: "param names are camelCase",
+	Severity:    Warning,
+	Check: func(doc *Document, report ReportFunc) {
+		eachMethod(doc, func(path string, m *openrpc.Method) {
+			for i, p := range m.Params {
+				if p != nil && !camelCase.MatchString(p.Name) {
+					report(fmt.Sprintf("%s/params/%d", path, i), "param %s of method %s is not camelCase", p.Name, m.Name)
+				}
+			}
+		})
+	},
+}
+
+// NoAnyResult forbids results that accept any value, such as {} or the anything schema of registries
+var NoAnyResult = Rule{
+	Name:        "no-any-result",
+	Description: "results are not anything schemas",
+	Severity:    Warning,
+	Check: func(doc *Document, report ReportFunc) {
+		eachMethod(doc, func(path string, m *openrpc.Method) {
+			if m.Result == nil {
+				return
+			}
+
+			sch, err := doc.Resolver.Schema(m.Result)
+			if err != nil {
+				return
+			}
+
+			if sch, err = doc.Resolver.Deref(sch); err != nil {
+				return
+			}
+
+			if acceptsAnything(sch) {
+				report(path+"/result", "result of method %s accepts any value", m.Name)
+			}
+		})
+	},
+}
+
+// acceptsAnything reports whether a decoded schema has no constraint; descriptions and titles are not constraints
+func acceptsAnything(sch interface{}) bool {
+
+	if b, ok := sch.(bool); ok {
+		return b
+	}
+
+	m, ok := sch.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	for k := range m {
+		switch k {
+		case "title", "description", "$comment", "examples", "default", "deprecated", "readOnly", "writeOnly":
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// DeprecatedDescription requires deprecated methods to have a description, telling what to use instead
+var DeprecatedDescription = Rule{
+	Name:        "deprecated-description",
+	Description: "deprecated methods carry a description",
+	Severity:    Warning,
+	Check: func(doc *Document, report ReportFunc) {
+		eachMethod(doc, func(path string, m *openrpc.Method) {
+			if m.Deprecated && m.Description == "" {
+				report(path, "deprecated method %s has no description", m.Name)
+			}
+		})
+	},
+}
+
+// DeclaredTags requires the tags of methods to be declared in components/tags
+var DeclaredTags = Rule{
+	Name:        "declared-tags",
+	Description: "the tags of methods are declared in components/tags",
+	Severity:    Warning,
+	Check: func(doc *Document, report ReportFunc) {
+		tags := openrpc.NewTagRegistry()
+		if doc.Components != nil && doc.Components.Tags != nil {
+			tags = doc.Components.Tags
+		}
+
+		eachMethod(doc, func(path string, m *openrpc.Method) {
+			for i, t := range m.Tags {
+				if _, ok := tags.Tag(t.Name); !ok {
+					report(fmt.Sprintf("%s/tags/%d", path, i), "tag %s of method %s is not declared", t.Name, m.Name)
+				}
+			}
+		})
+	},
+}
+
+// UniqueErrorCodes forbids methods from declaring two errors with the same code
+var UniqueErrorCodes = Rule{
+	Name:        "unique-error-codes",
+	Description: "the error codes of a method are unique",
+	Severity:    Error,
+	Check: func(doc *Document, report ReportFunc) {
+		eachMethod(doc, func(path string, m *openrpc.Method) {
+			seen := map[int]bool{}
+
+			for i, e := range m.Errors {
+				e, err := doc.Resolver.Error(e)
+				if err != nil {
+					continue
+				}
+
+				if seen[e.Code] {
+					report(fmt.Sprintf("%s/errors/%d", path, i), "error code %d of method %s is declared more than once", e.Code, m.Name)
+				}
+				seen[e.Code] = true
+			}
+		})
+	},
+}
+
+// ExamplesExist requires every method to have at least one example pairing
+var ExamplesExist = Rule{
+	Name:        "examples-exist",
+	Description: "every method has examples",
+	Severity:    Warning,
+	Check: func(doc *Document, report ReportFunc) {
+		eachMethod(doc, func(path string, m *openrpc.Method) {
+			if len(m.Examples) == 0 {
+				report(path, "method %s has no examples", m.Name)
+			}
+		})
+	},
+}
+
+// eachMethod calls fn with the path of every method of doc, with its references to components resolved;
+// methods whose references do not resolve are left to validation
+func eachMethod(doc *Document, fn func(path string, m *openrpc.Method)) {
+
+	for i, m := range doc.Methods {
+		if m == nil {
+			continue
+		}
+
+		if resolved, err := doc.ResolveMethod(m); err == nil {
+			fn(fmt.Sprintf("methods/%d", i), resolved)
+		}
+	}
+}
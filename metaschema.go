@@ -0,0 +1,307 @@
+package openrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	jsch "github.com/qri-io/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// MetaSchema is the json schema of openrpc 1.x documents, after the meta-schema published with the specification:
+// objects only accept the fields the specification defines and x- extensions, and schemas are only checked to be
+// objects or booleans. Other fields are checked against the unknownField definition rather than rejected with
+// additionalProperties false, so that errors locate them instead of the object declaring them
+const MetaSchema = `{
+	"title": "openrpcDocument",
+	"type": "object",
+	"required": ["openrpc", "info", "methods"],
+	"properties": {
+		"$schema": {"type": "string"},
+		"openrpc": {"type": "string", "pattern": "^1\\.\\d+\\.\\d+$"},
+		"info": {"$ref": "#/$defs/infoObject"},
+		"servers": {"type": "array", "items": {"$ref": "#/$defs/serverObject"}},
+		"methods": {"type": "array", "items": {"$ref": "#/$defs/methodObject"}},
+		"components": {"$ref": "#/$defs/componentsObject"},
+		"externalDocs": {"$ref": "#/$defs/externalDocumentationObject"}
+	},
+	"patternProperties": {"^x-": {}},
+	"additionalProperties": {"$ref": "#/$defs/unknownField"},
+	"$defs": {
+		"unknownField": {"not": {}},
+		"referenceObject": {
+			"type": "object",
+			"required": ["$ref"],
+			"properties": {"$ref": {"type": "string"}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"schemaObject": {"type": ["object", "boolean"]},
+		"infoObject": {
+			"type": "object",
+			"required": ["title", "version"],
+			"properties": {
+				"title": {"type": "string"},
+				"description": {"type": "string"},
+				"termsOfService": {"type": "string"},
+				"version": {"type": "string"},
+				"contact": {"$ref": "#/$defs/contactObject"},
+				"license": {"$ref": "#/$defs/licenseObject"}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"contactObject": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"email": {"type": "string"},
+				"url": {"type": "string"}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"licenseObject": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"url": {"type": "string"}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"serverObject": {
+			"type": "object",
+			"required": ["url"],
+			"properties": {
+				"url": {"type": "string"},
+				"name": {"type": "string"},
+				"description": {"type": "string"},
+				"summary": {"type": "string"},
+				"variables": {"type": "object", "additionalProperties": {"$ref": "#/$defs/serverVariableObject"}}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"serverVariableObject": {
+			"type": "object",
+			"required": ["default"],
+			"properties": {
+				"default": {"type": "string"},
+				"description": {"type": "string"},
+				"enum": {"type": "array", "items": {"type": "string"}}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"externalDocumentationObject": {
+			"type": "object",
+			"required": ["url"],
+			"properties": {
+				"description": {"type": "string"},
+				"url": {"type": "string"}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"methodObject": {
+			"type": "object",
+			"required": ["name", "params"],
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"description": {"type": "string"},
+				"summary": {"type": "string"},
+				"servers": {"type": "array", "items": {"$ref": "#/$defs/serverObject"}},
+				"tags": {"type": "array", "items": {"oneOf": [{"$ref": "#/$defs/tagObject"}, {"$ref": "#/$defs/referenceObject"}]}},
+				"paramStructure": {"type": "string", "enum": ["by-position", "by-name", "either"]},
+				"params": {"type": "array", "items": {"oneOf": [{"$ref": "#/$defs/contentDescriptorObject"}, {"$ref": "#/$defs/referenceObject"}]}},
+				"result": {"oneOf": [{"$ref": "#/$defs/contentDescriptorObject"}, {"$ref": "#/$defs/referenceObject"}]},
+				"errors": {"type": "array", "items": {"oneOf": [{"$ref": "#/$defs/errorObject"}, {"$ref": "#/$defs/referenceObject"}]}},
+				"links": {"type": "array", "items": {"oneOf": [{"$ref": "#/$defs/linkObject"}, {"$ref": "#/$defs/referenceObject"}]}},
+				"examples": {"type": "array", "items": {"oneOf": [{"$ref": "#/$defs/examplePairingObject"}, {"$ref": "#/$defs/referenceObject"}]}},
+				"deprecated": {"type": "boolean"},
+				"externalDocs": {"$ref": "#/$defs/externalDocumentationObject"}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"contentDescriptorObject": {
+			"type": "object",
+			"required": ["name", "schema"],
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"description": {"type": "string"},
+				"summary": {"type": "string"},
+				"schema": {"$ref": "#/$defs/schemaObject"},
+				"required": {"type": "boolean"},
+				"deprecated": {"type": "boolean"}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"errorObject": {
+			"type": "object",
+			"required": ["code", "message"],
+			"properties": {
+				"code": {"type": "integer"},
+				"message": {"type": "string"},
+				"data": {}
+			},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"linkObject": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"summary": {"type": "string"},
+				"description": {"type": "string"},
+				"method": {"type": "string"},
+				"params": {},
+				"server": {"$ref": "#/$defs/serverObject"}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"exampleObject": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"summary": {"type": "string"},
+				"description": {"type": "string"},
+				"value": {},
+				"externalValue": {"type": "string"}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"examplePairingObject": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"description": {"type": "string"},
+				"summary": {"type": "string"},
+				"params": {"type": "array", "items": {"oneOf": [{"$ref": "#/$defs/exampleObject"}, {"$ref": "#/$defs/referenceObject"}]}},
+				"result": {"oneOf": [{"$ref": "#/$defs/exampleObject"}, {"$ref": "#/$defs/referenceObject"}]}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"tagObject": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string", "minLength": 1},
+				"description": {"type": "string"},
+				"summary": {"type": "string"},
+				"externalDocs": {"$ref": "#/$defs/externalDocumentationObject"}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		},
+		"componentsObject": {
+			"type": "object",
+			"properties": {
+				"schemas": {"type": "object", "additionalProperties": {"$ref": "#/$defs/schemaObject"}},
+				"links": {"type": "object", "additionalProperties": {"$ref": "#/$defs/linkObject"}},
+				"errors": {"type": "object", "additionalProperties": {"$ref": "#/$defs/errorObject"}},
+				"examples": {"type": "object", "additionalProperties": {"$ref": "#/$defs/exampleObject"}},
+				"examplePairingObjects": {"type": "object", "additionalProperties": {"$ref": "#/$defs/examplePairingObject"}},
+				"contentDescriptors": {"type": "object", "additionalProperties": {"$ref": "#/$defs/contentDescriptorObject"}},
+				"tags": {"type": "object", "additionalProperties": {"$ref": "#/$defs/tagObject"}}
+			},
+			"patternProperties": {"^x-": {}},
+			"additionalProperties": {"$ref": "#/$defs/unknownField"}
+		}
+	}
+}`
+
+var (
+	metaSchemaOnce sync.Once
+	metaSchema     *jsch.Schema
+)
+
+// compiledMetaSchema returns the compiled MetaSchema
+func compiledMetaSchema() *jsch.Schema {
+
+	metaSchemaOnce.Do(func() {
+		metaSchema = &jsch.Schema{}
+		if err := json.Unmarshal([]byte(MetaSchema), metaSchema); err != nil {
+			panic("invalid meta-schema: " + err.Error())
+		}
+	})
+
+	return metaSchema
+}
+
+// unknownFieldMessage is the message of the validator for values matching the unknownField definition of MetaSchema
+const unknownFieldMessage = "result was valid, ('not') expected invalid"
+
+// metaSchemaErrors returns the errors of a json encoded document against MetaSchema
+func metaSchemaErrors(data []byte) ValidationErrors {
+
+	keyErrs, err := compiledMetaSchema().ValidateBytes(context.Background(), data)
+	if err != nil {
+		return ValidationErrors{{Message: err.Error()}}
+	}
+
+	var errs ValidationErrors
+
+	for _, e := range keyErrs {
+		msg := e.Message
+		if msg == unknownFieldMessage {
+			msg = "is not a field of the openrpc specification"
+		}
+
+		errs = append(errs, &ValidationError{Path: strings.TrimPrefix(e.PropertyPath, "/"), Message: msg})
+	}
+
+	// the validator walks objects in no particular order
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Path < errs[j].Path })
+
+	return errs
+}
+
+// ValidateDocument checks a json encoded document against MetaSchema, then parses and validates it, see Validate.
+// Unlike Validate, it reports the fields the specification does not define and the fields of the wrong type as
+// ValidationErrors, instead of ignoring them or failing to decode the document
+func ValidateDocument(data []byte) error {
+
+	doc, err := ParseDocument(data)
+	if err != nil {
+		if errs := metaSchemaErrors(data); len(errs) > 0 {
+			return errs
+		}
+		return err
+	}
+
+	if err := doc.Validate(); err != nil {
+		return err
+	}
+
+	if errs := metaSchemaErrors(data); len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// ValidateDocumentYAML is ValidateDocument for yaml encoded documents
+func ValidateDocumentYAML(data []byte) error {
+
+	var node yaml.Node
+
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+
+	if err := nodeToJSON(&b, &node); err != nil {
+		return err
+	}
+
+	return ValidateDocument(b.Bytes())
+}
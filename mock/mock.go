@@ -0,0 +1,222 @@
+// Package mock serves a stand-in JSON-RPC 2.0 endpoint for the methods of an openrpc document
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	"github.com/octanolabs/g0penrpc/internal/docutil"
+	"github.com/octanolabs/g0penrpc/internal/jsonrpc"
+)
+
+const (
+	// ErrorHeader selects an error declared by the method to respond with, by code or message
+	ErrorHeader = "X-Mock-Error"
+	// ErrorParam does the same as ErrorHeader from the params of a by-name request; it is removed before the params are matched
+	ErrorParam = "mockError"
+)
+
+// Server responds to the methods of a document: with the result of the first example pairing whose params match
+// the request, or with a result synthesized from the result schema when none does
+type Server struct {
+	res     *openrpc.RefResolver
+	methods map[string]*openrpc.Method
+}
+
+// NewServer returns a Server for the methods of doc; later changes to doc are not seen by the server
+func NewServer(doc *openrpc.DocumentSpec1) (*Server, error) {
+
+	res, err := openrpc.NewRefResolver(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{res: res, methods: map[string]*openrpc.Method{}}
+
+	methods, err := doc.ResolveMethods()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range methods {
+		if m != nil {
+			s.methods[m.Name] = m
+		}
+	}
+
+	return s, nil
+}
+
+// Call returns the result of method for the json encoded params, or the error it responds with;
+// simulate is the code or message of a declared error to respond with, the result is returned if it is empty
+func (s *Server) Call(method string, params json.RawMessage, simulate string) (interface{}, *openrpc.Error) {
+
+	m, ok := s.methods[method]
+	if !ok {
+		return nil, newError(openrpc.MethodNotFound(), "")
+	}
+
+	named, err := s.params(m, params)
+	if err != nil {
+		return nil, newError(openrpc.InvalidParams(), err.Error())
+	}
+
+	if v, ok := named[ErrorParam]; ok {
+		delete(named, ErrorParam)
+
+		if simulate == "" {
+			simulate = strings.Trim(string(v), `"`)
+		}
+	}
+
+	if simulate != "" {
+		return nil, s.declaredError(m, simulate)
+	}
+
+	for _, cd := range m.Params {
+		if cd == nil {
+			continue
+		}
+
+		if _, ok := named[cd.Name]; cd.Required && !ok {
+			return nil, newError(openrpc.InvalidParams(), "missing "+cd.Name)
+		}
+	}
+
+	for _, ex := range m.Examples {
+		if ex != nil && ex.Result != nil && matches(m, ex, named) {
+			return ex.Result.Value, nil
+		}
+	}
+
+	if m.Result == nil {
+		return nil, nil
+	}
+
+	sch, err := s.res.Schema(m.Result)
+	if err != nil {
+		return nil, newError(openrpc.InternalError(), err.Error())
+	}
+
+	v, err := Synthesize(s.res, sch)
+	if err != nil {
+		return nil, newError(openrpc.InternalError(), "cannot synthesize result: "+err.Error())
+	}
+
+	return v, nil
+}
+
+// params returns by-position and by-name params by name
+func (s *Server) params(m *openrpc.Method, raw json.RawMessage) (map[string]json.RawMessage, error) {
+
+	named := map[string]json.RawMessage{}
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return named, nil
+	}
+
+	var positional []json.RawMessage
+
+	if err := json.Unmarshal(raw, &positional); err == nil {
+		if len(positional) > len(m.Params) {
+			return nil, errors.New("too many params")
+		}
+
+		for i, p := range positional {
+			if m.Params[i] != nil {
+				named[m.Params[i].Name] = p
+			}
+		}
+
+		return named, nil
+	}
+
+	if err := json.Unmarshal(raw, &named); err != nil {
+		return nil, err
+	}
+
+	return named, nil
+}
+
+// matches reports whether the params of an example are the params of a request
+func matches(m *openrpc.Method, ex *openrpc.ExamplePairing, named map[string]json.RawMessage) bool {
+
+	params := docutil.NamedParams(m, ex)
+
+	if len(ex.Params) != len(named) || len(params) != len(named) {
+		return false
+	}
+
+	for name, v := range params {
+		raw, ok := named[name]
+		if !ok || !equal(raw, v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equal compares a json encoded value with a decoded one
+func equal(raw json.RawMessage, v interface{}) bool {
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+
+	var a, b interface{}
+
+	if json.Unmarshal(raw, &a) != nil || json.Unmarshal(data, &b) != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// declaredError returns the error of m whose code or message is simulate
+func (s *Server) declaredError(m *openrpc.Method, simulate string) *openrpc.Error {
+
+	code, err := strconv.Atoi(simulate)
+
+	for _, declared := range m.Errors {
+		e, resErr := s.res.Error(declared)
+		if resErr != nil {
+			continue
+		}
+
+		if (err == nil && e.Code == code) || e.Message == simulate {
+			return &e
+		}
+	}
+
+	return newError(openrpc.InternalError(), "method "+m.Name+" declares no error "+simulate)
+}
+
+// ServeHTTP serves single and batch JSON-RPC requests
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	simulate := r.Header.Get(ErrorHeader)
+
+	jsonrpc.ServeHTTP(w, r, func(ctx context.Context, method string, params json.RawMessage) (interface{}, *jsonrpc.Error) {
+		res, err := s.Call(method, params, simulate)
+		return res, jsonrpc.NewError(err)
+	})
+}
+
+// newError returns a copy of a predefined error, with detail appended to its message if it is not empty;
+// the mock is a development tool, so internal errors tell what went wrong
+func newError(e openrpc.Error, detail string) *openrpc.Error {
+
+	if detail != "" {
+		e.Message += ": " + detail
+	}
+
+	return &e
+}
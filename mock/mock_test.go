@@ -0,0 +1,232 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	"github.com/octanolabs/g0penrpc/internal/testutil"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	s, err := NewServer(testutil.Document(t, "petstore.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func post(t *testing.T, srv *httptest.Server, body string, header http.Header) string {
+	t.Helper()
+
+	req, err := http.NewRequest("POST", srv.URL, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+func TestServer(t *testing.T) {
+
+	srv := newServer(t)
+
+	cases := []struct {
+		name     string
+		body     string
+		header   http.Header
+		expected string
+	}{
+		{
+			"example",
+			`{"jsonrpc":"2.0","id":1,"method":"list_pets","params":[1]}`,
+			nil,
+			`{"jsonrpc":"2.0","id":1,"result":[{"id":1,"name":"Rex"}]}`,
+		},
+		{
+			"exampleByName",
+			`{"jsonrpc":"2.0","id":1,"method":"list_pets","params":{"limit":1}}`,
+			nil,
+			`{"jsonrpc":"2.0","id":1,"result":[{"id":1,"name":"Rex"}]}`,
+		},
+		{
+			"synthesized",
+			`{"jsonrpc":"2.0","id":2,"method":"list_pets","params":[5]}`,
+			nil,
+			`{"jsonrpc":"2.0","id":2,"result":[{"id":1416,"location":{},"name":"Fido"},{"id":1927,"name":"Fido","tag":null}]}`,
+		},
+		{
+			"recursive",
+			`{"jsonrpc":"2.0","id":3,"method":"family_tree"}`,
+			nil,
+			`{"jsonrpc":"2.0","id":3,"result":{"pet":{"born":"2057-08-11","id":1206,"name":"Fido"}}}`,
+		},
+		{
+			"missingParam",
+			`{"jsonrpc":"2.0","id":4,"method":"create_pet","params":{"kind":"dog"}}`,
+			nil,
+			`{"jsonrpc":"2.0","id":4,"error":{"code":-32602,"message":"Invalid params: missing name"}}`,
+		},
+		{
+			"errorHeader",
+			`{"jsonrpc":"2.0","id":5,"method":"create_pet","params":{"name":"Rex"}}`,
+			http.Header{ErrorHeader: {"1001"}},
+			`{"jsonrpc":"2.0","id":5,"error":{"code":1001,"message":"Name taken","data":{"name":"Rex"}}}`,
+		},
+		{
+			"errorParam",
+			`{"jsonrpc":"2.0","id":6,"method":"create_pet","params":{"name":"Rex","mockError":"Store full"}}`,
+			nil,
+			`{"jsonrpc":"2.0","id":6,"error":{"code":1002,"message":"Store full"}}`,
+		},
+		{
+			"undeclaredError",
+			`{"jsonrpc":"2.0","id":7,"method":"list_pets"}`,
+			http.Header{ErrorHeader: {"1001"}},
+			`{"jsonrpc":"2.0","id":7,"error":{"code":-32603,"message":"Internal error: method list_pets declares no error 1001"}}`,
+		},
+		{
+			"methodNotFound",
+			`{"jsonrpc":"2.0","id":8,"method":"delete_pet"}`,
+			nil,
+			`{"jsonrpc":"2.0","id":8,"error":{"code":-32601,"message":"Method not found"}}`,
+		},
+		{
+			"batch",
+			`[{"jsonrpc":"2.0","id":9,"method":"list_pets","params":[1]},{"jsonrpc":"2.0","method":"list_pets"},{"jsonrpc":"1.0"}]`,
+			nil,
+			`[{"jsonrpc":"2.0","id":9,"result":[{"id":1,"name":"Rex"}]},{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"Invalid Request"}}]`,
+		},
+		{
+			"parseError",
+			`{"jsonrpc"`,
+			nil,
+			`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if out := post(t, srv, c.body, c.header); out != c.expected {
+				t.Errorf("error, expected\n%s\ngot\n%s", c.expected, out)
+			}
+		})
+	}
+}
+
+func TestServerNullParam(t *testing.T) {
+
+	doc, err := openrpc.ParseDocument([]byte(`{
+		"openrpc": "1.2.6",
+		"info": {"title": "null", "version": "1"},
+		"methods": [{"name": "ping", "params": [null], "result": {"name": "pong", "schema": {"const": "pong"}}}]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewServer(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, rpcErr := s.Call("ping", nil, "")
+	if rpcErr != nil || res != "pong" {
+		t.Errorf("error, expected pong, got %v, %v", res, rpcErr)
+	}
+}
+
+// TestSynthesize checks that synthesized values validate against their schema, and are the same for a schema every time
+func TestSynthesize(t *testing.T) {
+
+	res, err := openrpc.NewRefResolver(&openrpc.DocumentSpec1{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, schema := range []string{
+		`{"type":"integer","exclusiveMinimum":3}`,
+		`{"type":"integer","minimum":1.5,"maximum":3}`,
+		`{"type":"number","maximum":-2.5}`,
+		`{"type":"number","multipleOf":0.1,"minimum":0.05,"maximum":1}`,
+		`{"type":"string","minLength":3}`,
+		`{"type":"string","pattern":"^[a-f0-9]{8}$"}`,
+		`{"type":"string","maxLength":2}`,
+		`{"type":"string","format":"email","default":"user@example.com"}`,
+		`{"type":"array","items":{"type":"boolean"},"minItems":2}`,
+		`{"oneOf":[{"type":"null"},{"type":"string"}]}`,
+		`{"allOf":[{"properties":{"a":{"const":1}}},{"properties":{"b":{"default":"x"}},"required":["b"]}]}`,
+		`{}`,
+	} {
+		var sch interface{}
+		if err := json.Unmarshal([]byte(schema), &sch); err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := Synthesize(res, sch)
+		if err != nil {
+			t.Errorf("error, schema %s: %v", schema, err)
+			continue
+		}
+
+		again, err := Synthesize(res, sch)
+		if err != nil || !reflect.DeepEqual(v, again) {
+			t.Errorf("error, schema %s: synthesized %v, then %v", schema, v, again)
+		}
+
+		rs, err := res.Compile(sch)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		errs, err := rs.ValidateBytes(context.Background(), data)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(errs) > 0 {
+			t.Errorf("error, schema %s: synthesized %s does not validate: %v", schema, data, errs)
+		}
+	}
+
+	var sch interface{}
+	if err := json.Unmarshal([]byte(`{"$ref":"#/components/schemas/Missing"}`), &sch); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Synthesize(res, sch); err == nil {
+		t.Errorf("error, expected an unresolved reference to fail")
+	}
+}
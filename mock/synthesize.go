@@ -0,0 +1,25 @@
+package mock
+
+import (
+	openrpc "github.com/octanolabs/g0penrpc"
+	"github.com/octanolabs/g0penrpc/fuzz"
+)
+
+// seed seeds the generation of synthesized values, so that a schema always gives the same value
+const seed = 1
+
+// Synthesize returns a value conforming to a decoded json schema, resolving references with res. The value is
+// generated by a fuzz.Generator with a fixed seed; schemas declaring a default or examples give the default,
+// or their first example
+func Synthesize(res *openrpc.RefResolver, sch interface{}) (interface{}, error) {
+
+	var r fuzz.Resolver
+	if res != nil {
+		r = res
+	}
+
+	g := fuzz.New(seed, r)
+	g.Examples = true
+
+	return g.Valid(sch)
+}
@@ -0,0 +1,173 @@
+package openrpc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// NamingStrategy names the schemas a SchemaRegistry creates for go types
+type NamingStrategy interface {
+	// TypeName returns the name of a type that is neither a pointer, an interface nor an unnamed slice, array or map
+	TypeName(t reflect.Type) string
+	// SliceName returns the name of a slice or an array given the name of its elements
+	SliceName(elem string) string
+	// MapName returns the name of a map given the name of its values
+	MapName(elem string) string
+}
+
+// Naming is a NamingStrategy built from functions; a nil function falls back to the corresponding ShortNames one
+type Naming struct {
+	Type  func(t reflect.Type) string
+	Slice func(elem string) string
+	Map   func(elem string) string
+}
+
+func (n Naming) TypeName(t reflect.Type) string {
+
+	if n.Type != nil {
+		return n.Type(t)
+	}
+
+	return shortTypeName(t)
+}
+
+func (n Naming) SliceName(elem string) string {
+
+	if n.Slice != nil {
+		return n.Slice(elem)
+	}
+
+	return elem + "[]"
+}
+
+func (n Naming) MapName(elem string) string {
+
+	if n.Map != nil {
+		return n.Map(elem)
+	}
+
+	return "Object[" + elem + "]"
+}
+
+var (
+	// ShortNames names types after the last element of their package path, e.g. types.Block, types.Block[] and Object[types.Block];
+	// it is the default strategy
+	ShortNames NamingStrategy = Naming{}
+	// FullNames names types after their full import path, e.g. github.com/org/repo/types.Block
+	FullNames NamingStrategy = Naming{Type: fullTypeName}
+)
+
+// NamingFunc returns a NamingStrategy that names types with f, and slices and maps like ShortNames
+func NamingFunc(f func(t reflect.Type) string) NamingStrategy {
+	return Naming{Type: f}
+}
+
+// CollisionPolicy selects what a SchemaRegistry does when two different types get the same name
+type CollisionPolicy int
+
+const (
+	// Disambiguate appends a numeric suffix to the name of the type registered last, e.g. types.Block_2
+	Disambiguate CollisionPolicy = iota
+	// FailOnCollision makes the registration of the type registered last fail
+	FailOnCollision
+)
+
+func shortTypeName(t reflect.Type) string {
+
+	name := typeName(t)
+
+	s := strings.Split(t.PkgPath(), "/")
+	if pkgName := s[len(s)-1]; pkgName != "" {
+		return pkgName + "." + name
+	}
+
+	return name
+}
+
+func fullTypeName(t reflect.Type) string {
+
+	name := typeName(t)
+
+	if t.PkgPath() != "" {
+		return t.PkgPath() + "." + name
+	}
+
+	return name
+}
+
+// typeName returns the name of t, or the last part of its description if t is unnamed
+func typeName(t reflect.Type) string {
+
+	if name := t.Name(); name != "" {
+		return name
+	}
+
+	desc := t.String()
+	idx := strings.LastIndexFunc(desc, func(r rune) bool {
+		return r == ']' || r == '*'
+	})
+
+	return desc[idx+1:]
+}
+
+// nameOf returns the name of the schema of type t, resolving collisions between different types
+// according to the collision policy of the registry
+func (s *SchemaRegistry) nameOf(t reflect.Type) (string, error) {
+
+	naming := s.naming
+	if naming == nil {
+		naming = ShortNames
+	}
+
+	if t.Kind() == reflect.Interface {
+		return "anything", nil
+	}
+
+	// named slices and maps keep their own name
+	switch kind := t.Kind(); {
+	case kind == reflect.Ptr:
+		return s.nameOf(t.Elem())
+	case t.Name() != "":
+	case kind == reflect.Slice, kind == reflect.Array:
+		elem, err := s.nameOf(t.Elem())
+		if err != nil {
+			return "", err
+		}
+
+		return naming.SliceName(elem), nil
+	case kind == reflect.Map:
+		elem, err := s.nameOf(t.Elem())
+		if err != nil {
+			return "", err
+		}
+
+		return naming.MapName(elem), nil
+	}
+
+	if name, ok := s.typeNames[t]; ok {
+		return name, nil
+	}
+
+	if s.typeNames == nil {
+		s.typeNames, s.namedTypes = map[reflect.Type]string{}, map[string]reflect.Type{}
+	}
+
+	name := naming.TypeName(t)
+
+	if other, ok := s.namedTypes[name]; ok {
+		if s.collisions == FailOnCollision {
+			return "", fmt.Errorf("types %s and %s are both named %s", fullTypeName(other), fullTypeName(t), name)
+		}
+
+		base := name
+		for i := 2; ok; i++ {
+			name = fmt.Sprintf("%s_%d", base, i)
+			_, ok = s.namedTypes[name]
+		}
+	}
+
+	s.typeNames[t], s.namedTypes[name] = name, t
+
+	return name, nil
+}
@@ -2,6 +2,7 @@
 package openrpc
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -20,9 +21,6 @@ func NewDocument(methods []*Method, info *Info) *DocumentSpec1 {
 	}
 }
 
-//TODO:
-// - figure out why integer and number schemas are empty;
-
 func MakeSchema(t reflect.Type, schema Schema) error {
 	switch t.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
@@ -58,7 +56,10 @@ func buildStructSchema(t reflect.Type, schema Schema) error {
 
 	//methods with pointer receiver should be tested against pointer to structs/etc
 
-	props := map[string]Schema{}
+	// properties are written in declaration order
+	var props bytes.Buffer
+
+	props.WriteByte('{')
 
 	for i := 0; i < t.NumField(); i++ {
 		field := t.Field(i)
@@ -69,10 +70,25 @@ func buildStructSchema(t reflect.Type, schema Schema) error {
 		if err != nil {
 			return errors.New("error handling struct type: " + fmt.Sprintf("%v.%v field: %v :", t.PkgPath(), t.Name(), field.Name) + err.Error())
 		}
-		props[field.Name] = sch
+
+		data, err := sch.MarshalJSON()
+		if err != nil {
+			return err
+		}
+
+		name, _ := json.Marshal(field.Name)
+
+		if i > 0 {
+			props.WriteByte(',')
+		}
+		props.Write(name)
+		props.WriteByte(':')
+		props.Write(data)
 	}
 
-	s["properties"] = props
+	props.WriteByte('}')
+
+	s["properties"] = json.RawMessage(props.Bytes())
 
 	data, err := json.Marshal(s)
 	if err != nil {
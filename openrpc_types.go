@@ -4,9 +4,9 @@ const (
 	// https://json-schema.org/understanding-json-schema/reference/type.html
 	stringSchema string = `{ "type": "string", "pattern": "(.*)" }`
 	boolSchema   string = `{ "type": "boolean", "pattern": "(true|false)" }`
-	// using [0-9] instead of \d because json returns an error
+	// using [0-9] instead of \d because json returns an error, the dot is escaped for both the regexp and json
 	integerSchema string = `{ "type": "integer", "pattern": "(^[0-9]*$)" }`
-	numberSchema  string = `{ "type": "number", "pattern": "^([0-9]*\.[0-9]+)$|^([0-9]*)$" }`
+	numberSchema  string = `{ "type": "number", "pattern": "^([0-9]*\\.[0-9]+)$|^([0-9]*)$" }`
 	anySchema     string = `{}`
 	nullSchema    string = `{ "type": "null" }`
 )
@@ -62,13 +62,13 @@ type Method struct {
 }
 
 type Components struct {
-	ContentDescriptors    *SchemaRegistry `json:"contentDescriptors,omitempty"`
-	Schemas               *SchemaRegistry `json:"schemas,omitempty"`
-	Examples              *SchemaRegistry `json:"examples,omitempty"`
-	Links                 *SchemaRegistry `json:"links,omitempty"`
-	Errors                *SchemaRegistry `json:"errors,omitempty"`
-	ExamplePairingObjects *SchemaRegistry `json:"examplePairingObjects,omitempty"`
-	Tags                  *SchemaRegistry `json:"tags,omitempty"`
+	ContentDescriptors    *ContentDescriptorRegistry `json:"contentDescriptors,omitempty"`
+	Schemas               *SchemaRegistry            `json:"schemas,omitempty"`
+	Examples              *ExampleRegistry           `json:"examples,omitempty"`
+	Links                 *LinkRegistry              `json:"links,omitempty"`
+	Errors                *ErrorRegistry             `json:"errors,omitempty"`
+	ExamplePairingObjects *ExamplePairingRegistry    `json:"examplePairingObjects,omitempty"`
+	Tags                  *TagRegistry               `json:"tags,omitempty"`
 }
 
 type ContentDescriptor struct {
@@ -78,6 +78,13 @@ type ContentDescriptor struct {
 	Required                     bool   `json:"required,omitempty"`
 	Deprecated                   bool   `json:"deprecated,omitempty"`
 	Schema/* required */ Pointer        `json:"schema"`
+
+	// InlineSchema holds the schema when it is declared in place rather than referenced by Schema
+	InlineSchema Schema `json:"-"`
+
+	// Ref references a content descriptor declared in components/contentDescriptors; when it is set the content
+	// descriptor is written as a reference only
+	Ref Pointer `json:"-"`
 }
 
 type ExternalDocs struct {
@@ -103,12 +110,18 @@ type Tag struct {
 	Summary                   string        `json:"summary,omitempty"`
 	Description               string        `json:"description,omitempty"`
 	ExternalDocs              *ExternalDocs `json:"externalDocs,omitempty"`
+
+	// Ref references a tag declared in components/tags; when it is set the tag is written as a reference only
+	Ref Pointer `json:"-"`
 }
 
 type Error struct {
 	Code/* required */ int                   `json:"code"`
 	Message/* required */ string             `json:"message"`
 	Data                         interface{} `json:"data,omitempty"`
+
+	// Ref references an error declared in components/errors; when it is set the error is written as a reference only
+	Ref Pointer `json:"-"`
 }
 
 type Link struct {
@@ -118,6 +131,9 @@ type Link struct {
 	Method                    string                 `json:"method,omitempty"`
 	Params                    map[string]interface{} `json:"params,omitempty"`
 	Server                    *Server                `json:"server,omitempty"`
+
+	// Ref references a link declared in components/links; when it is set the link is written as a reference only
+	Ref Pointer `json:"-"`
 }
 
 type Example struct {
@@ -126,6 +142,9 @@ type Example struct {
 	Description   string      `json:"description,omitempty"`
 	Value         interface{} `json:"value,omitempty"`
 	ExternalValue string      `json:"externalValue,omitempty"`
+
+	// Ref references an example declared in components/examples; when it is set the example is written as a reference only
+	Ref Pointer `json:"-"`
 }
 
 type ExamplePairing struct {
@@ -134,4 +153,8 @@ type ExamplePairing struct {
 	Summary     string     `json:"summary,omitempty"`
 	Params      []*Example `json:"params,omitempty"`
 	Result      *Example   `json:"result,omitempty"`
+
+	// Ref references a pairing declared in components/examplePairingObjects; when it is set the pairing is written
+	// as a reference only
+	Ref Pointer `json:"-"`
 }
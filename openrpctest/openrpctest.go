@@ -0,0 +1,253 @@
+// Package openrpctest checks that a server implements the methods of an openrpc document as published
+package openrpctest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	"github.com/octanolabs/g0penrpc/fuzz"
+	"github.com/octanolabs/g0penrpc/internal/docutil"
+	jsch "github.com/qri-io/jsonschema"
+)
+
+// Options configures a contract test
+type Options struct {
+	// Seed seeds the generation of params
+	Seed int64
+	// Samples is the number of calls with generated params made for every method, 10 by default
+	Samples int
+	// Loader loads the external references of the document, if any
+	Loader openrpc.Loader
+}
+
+// Failure is a call whose response does not conform to the document
+type Failure struct {
+	Method string
+	// Case is the name of the example pairing the params come from, or "generated #n"
+	Case   string
+	Params json.RawMessage
+	Reason string
+}
+
+func (f Failure) String() string {
+	return f.Method + " (" + f.Case + ") params " + string(f.Params) + ": " + f.Reason
+}
+
+// Run calls every method of doc through transport, in a subtest per method, with the params of its example pairings
+// and with generated params; the test fails when a result does not validate against the result schema of the method,
+// or when an error has a code the method does not declare
+func Run(t *testing.T, doc *openrpc.DocumentSpec1, transport Transport) {
+	t.Helper()
+
+	RunWithOptions(t, doc, transport, Options{})
+}
+
+// RunWithOptions is Run with options
+func RunWithOptions(t *testing.T, doc *openrpc.DocumentSpec1, transport Transport, opts Options) {
+	t.Helper()
+
+	c, err := newChecker(doc, transport, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, m := range doc.Methods {
+		if m == nil {
+			continue
+		}
+
+		i := i
+		t.Run(m.Name, func(t *testing.T) {
+			failures, err := c.method(context.Background(), i)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, f := range failures {
+				t.Error(f.String())
+			}
+		})
+	}
+}
+
+// Check is Run outside of tests: it returns the calls whose response does not conform to doc, and fails when
+// the document cannot be prepared or the transport fails
+func Check(ctx context.Context, doc *openrpc.DocumentSpec1, transport Transport, opts Options) ([]Failure, error) {
+
+	c, err := newChecker(doc, transport, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []Failure
+
+	for i, m := range doc.Methods {
+		if m == nil {
+			continue
+		}
+
+		f, err := c.method(ctx, i)
+		if err != nil {
+			return failures, err
+		}
+
+		failures = append(failures, f...)
+	}
+
+	return failures, nil
+}
+
+type checker struct {
+	doc       *openrpc.DocumentSpec1
+	transport Transport
+	opts      Options
+	res       *openrpc.RefResolver
+	// results are the compiled result schemas of the methods, nil for methods without result
+	results []*jsch.Schema
+}
+
+func newChecker(doc *openrpc.DocumentSpec1, transport Transport, opts Options) (*checker, error) {
+
+	if opts.Samples == 0 {
+		opts.Samples = 10
+	}
+
+	bundled, err := openrpc.Bundle(doc, opts.Loader)
+	if err != nil {
+		return nil, err
+	}
+
+	// methods are checked with their references to components resolved
+	if bundled.Methods, err = bundled.ResolveMethods(); err != nil {
+		return nil, err
+	}
+
+	res, err := openrpc.NewRefResolver(bundled)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &checker{doc: bundled, transport: transport, opts: opts, res: res, results: make([]*jsch.Schema, len(bundled.Methods))}
+
+	for i, m := range bundled.Methods {
+		if m == nil || m.Result == nil {
+			continue
+		}
+
+		sch, err := res.Schema(m.Result)
+		if err != nil {
+			return nil, errors.New("method " + m.Name + ": " + err.Error())
+		}
+
+		// the schemas are compiled with their references, so that recursive results are checked
+		rs, err := res.Compile(sch)
+		if err != nil {
+			return nil, errors.New("method " + m.Name + ": invalid result schema: " + err.Error())
+		}
+
+		c.results[i] = rs
+	}
+
+	return c, nil
+}
+
+// method checks the method at index i of the document
+func (c *checker) method(ctx context.Context, i int) ([]Failure, error) {
+
+	m := c.doc.Methods[i]
+
+	var failures []Failure
+
+	call := func(name string, params json.RawMessage) error {
+		res, err := c.transport.Call(ctx, m.Name, params)
+		if err != nil {
+			return errors.New(m.Name + " (" + name + "): " + err.Error())
+		}
+
+		if reason := c.check(i, res); reason != "" {
+			failures = append(failures, Failure{Method: m.Name, Case: name, Params: params, Reason: reason})
+		}
+
+		return nil
+	}
+
+	for j, ex := range m.Examples {
+		if ex == nil {
+			continue
+		}
+
+		name := ex.Name
+		if name == "" {
+			name = "example #" + strconv.Itoa(j)
+		}
+
+		params, err := json.Marshal(docutil.ExampleParams(m, ex))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := call(name, params); err != nil {
+			return failures, err
+		}
+	}
+
+	g := fuzz.New(c.opts.Seed, c.res)
+
+	for j := 0; j < c.opts.Samples; j++ {
+		v, err := g.Params(m)
+		if err != nil {
+			return failures, errors.New(m.Name + ": cannot generate params: " + err.Error())
+		}
+
+		params, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := call("generated #"+strconv.Itoa(j), params); err != nil {
+			return failures, err
+		}
+	}
+
+	return failures, nil
+}
+
+// check returns why a response does not conform to the method at index i, or an empty string
+func (c *checker) check(i int, res *Response) string {
+
+	m := c.doc.Methods[i]
+
+	if res.Error != nil {
+		for _, declared := range m.Errors {
+			if e, err := c.res.Error(declared); err == nil && e.Code == res.Error.Code {
+				return ""
+			}
+		}
+
+		return "undeclared error " + strconv.Itoa(res.Error.Code) + " " + res.Error.Message
+	}
+
+	rs := c.results[i]
+	if rs == nil {
+		return ""
+	}
+
+	errs, err := rs.ValidateBytes(context.Background(), res.Result)
+	if err != nil {
+		return "invalid result " + string(res.Result) + ": " + err.Error()
+	}
+
+	if len(errs) > 0 {
+		reason := "result " + string(res.Result) + " does not match the result schema:"
+		for _, e := range errs {
+			reason += " " + e.Error() + ";"
+		}
+		return reason[:len(reason)-1]
+	}
+
+	return ""
+}
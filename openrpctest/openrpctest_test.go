@@ -0,0 +1,137 @@
+package openrpctest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	"github.com/octanolabs/g0penrpc/internal/testutil"
+	"github.com/octanolabs/g0penrpc/mock"
+)
+
+// TestRun runs the contract test against the mock server of the document, which conforms to it by construction
+func TestRun(t *testing.T) {
+
+	doc := testutil.Document(t, "petstore.json")
+
+	srv, err := mock.NewServer(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Run(t, doc, Handler(srv))
+
+	t.Run("http", func(t *testing.T) {
+		ts := httptest.NewServer(srv)
+		defer ts.Close()
+
+		RunWithOptions(t, doc, HTTP(ts.URL), Options{Seed: 7, Samples: 3})
+	})
+}
+
+func TestCheck(t *testing.T) {
+
+	doc := testutil.Document(t, "petstore.json")
+
+	var calls []string
+
+	// a drifted implementation: list_pets returns pets without names, create_pet fails with an undeclared error
+	drifted := TransportFunc(func(ctx context.Context, method string, params json.RawMessage) (*Response, error) {
+		calls = append(calls, method+" "+string(params))
+
+		switch method {
+		case "list_pets":
+			return &Response{Result: json.RawMessage(`[{"id":1}]`)}, nil
+		case "create_pet":
+			if strings.Contains(string(params), "Rex") {
+				return &Response{Error: &openrpc.Error{Code: 1001, Message: "Name taken"}}, nil
+			}
+			return &Response{Error: &openrpc.Error{Code: -32603, Message: "database down"}}, nil
+		case "ping":
+			return &Response{Result: json.RawMessage(`null`)}, nil
+		}
+
+		return &Response{Result: json.RawMessage(`{"pet":{"id":1,"name":"Rex"}}`)}, nil
+	})
+
+	failures, err := Check(context.Background(), doc, drifted, Options{Samples: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != 12 || calls[0] != "list_pets [1]" || calls[3] != `create_pet {"kind":"dog","name":"Rex"}` {
+		t.Errorf("error, unexpected calls %q", calls)
+	}
+
+	var reasons []string
+	for _, f := range failures {
+		reasons = append(reasons, f.Method+" ("+f.Case+"): "+f.Reason)
+	}
+
+	expected := []string{
+		`list_pets (firstPets): result [{"id":1}] does not match the result schema: /0: {"id":1} "name" value is required`,
+		`list_pets (generated #0): result [{"id":1}] does not match the result schema: /0: {"id":1} "name" value is required`,
+		`list_pets (generated #1): result [{"id":1}] does not match the result schema: /0: {"id":1} "name" value is required`,
+		`create_pet (generated #0): undeclared error -32603 database down`,
+		`create_pet (generated #1): undeclared error -32603 database down`,
+	}
+
+	if strings.Join(reasons, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("error, unexpected failures:\n%s", strings.Join(reasons, "\n"))
+	}
+}
+
+// TestCheckRecursive checks results against a schema that refers to itself
+func TestCheckRecursive(t *testing.T) {
+
+	doc, err := openrpc.ParseDocument([]byte(`{
+		"openrpc": "1.2.6",
+		"info": {"title": "tree", "version": "1.0.0"},
+		"methods": [{
+			"name": "tree",
+			"params": [],
+			"result": {"name": "root", "schema": {"$ref": "#/components/schemas/Node"}}
+		}],
+		"components": {"schemas": {"Node": {
+			"type": "object",
+			"required": ["name"],
+			"properties": {
+				"name": {"type": "string"},
+				"children": {"type": "array", "items": {"$ref": "#/components/schemas/Node"}}
+			}
+		}}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := TransportFunc(func(ctx context.Context, method string, params json.RawMessage) (*Response, error) {
+		return &Response{Result: json.RawMessage(`{"name":"a","children":[{"name":"b","children":[{"children":[]}]}]}`)}, nil
+	})
+
+	failures, err := Check(context.Background(), doc, tree, Options{Samples: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(failures) != 1 || !strings.Contains(failures[0].Reason, `"name" value is required`) {
+		t.Errorf("error, unexpected failures %v", failures)
+	}
+}
+
+func TestHTTPTransport(t *testing.T) {
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":42,"result":true}`))
+	}))
+	defer ts.Close()
+
+	_, err := HTTP(ts.URL).Call(context.Background(), "ping", nil)
+	if err == nil || err.Error() != "invalid response: expected id 1, got 42" {
+		t.Errorf("error, expected a mismatched id, got %v", err)
+	}
+}
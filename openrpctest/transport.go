@@ -0,0 +1,114 @@
+package openrpctest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+// Transport calls a method of the server under test
+type Transport interface {
+	// Call sends a request and returns its response; err is reserved for failures of the transport itself,
+	// JSON-RPC errors are returned in the response
+	Call(ctx context.Context, method string, params json.RawMessage) (*Response, error)
+}
+
+// TransportFunc adapts a function to a Transport
+type TransportFunc func(ctx context.Context, method string, params json.RawMessage) (*Response, error)
+
+func (f TransportFunc) Call(ctx context.Context, method string, params json.RawMessage) (*Response, error) {
+	return f(ctx, method, params)
+}
+
+// Response is the outcome of a call, either a result or an error
+type Response struct {
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *openrpc.Error  `json:"error,omitempty"`
+}
+
+// HTTP returns a Transport posting JSON-RPC 2.0 requests to url
+func HTTP(url string) Transport {
+	return &httpTransport{do: func(req *http.Request) (*http.Response, error) {
+		return http.DefaultClient.Do(req)
+	}, url: url}
+}
+
+// Handler returns a Transport serving requests with h in process, e.g. a generated dispatcher
+func Handler(h http.Handler) Transport {
+	return &httpTransport{do: func(req *http.Request) (*http.Response, error) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Result(), nil
+	}, url: "http://localhost/"}
+}
+
+type httpTransport struct {
+	do  func(req *http.Request) (*http.Response, error)
+	url string
+	id  int64
+}
+
+func (h *httpTransport) Call(ctx context.Context, method string, params json.RawMessage) (*Response, error) {
+
+	id := atomic.AddInt64(&h.id, 1)
+
+	body, err := json.Marshal(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      int64           `json:"id"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params,omitempty"`
+	}{"2.0", id, method, params})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := h.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Response
+	}
+
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, errors.New("invalid response (status " + strconv.Itoa(res.StatusCode) + "): " + string(data))
+	}
+
+	if msg.JSONRPC != "2.0" {
+		return nil, errors.New("invalid response: jsonrpc is not 2.0: " + string(data))
+	}
+
+	if string(msg.ID) != strconv.FormatInt(id, 10) {
+		return nil, errors.New("invalid response: expected id " + strconv.FormatInt(id, 10) + ", got " + string(msg.ID))
+	}
+
+	if msg.Result == nil && msg.Error == nil {
+		return nil, errors.New("invalid response: no result nor error: " + string(data))
+	}
+
+	return &msg.Response, nil
+}
@@ -0,0 +1,399 @@
+// Package record captures the calls served by a JSON-RPC handler and turns them into example pairings of a document
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+// Call is a successful call served by the recorded handler; numbers are decoded as json.Number
+type Call struct {
+	Method string
+	// Params is either an array or an object, nil if the request had none
+	Params interface{}
+	Result interface{}
+}
+
+// RedactFunc anonymizes a call in place before it is recorded; returning false drops the call
+type RedactFunc func(c *Call) bool
+
+// Redacted replaces the values removed by RedactKeys
+const Redacted = "REDACTED"
+
+// RedactKeys returns a RedactFunc replacing the values of object members named after one of keys, case insensitively,
+// at any depth of params and results
+func RedactKeys(keys ...string) RedactFunc {
+
+	set := map[string]bool{}
+	for _, k := range keys {
+		set[strings.ToLower(k)] = true
+	}
+
+	var redact func(v interface{}) interface{}
+
+	redact = func(v interface{}) interface{} {
+		switch n := v.(type) {
+		case map[string]interface{}:
+			for k, child := range n {
+				if set[strings.ToLower(k)] {
+					n[k] = Redacted
+				} else {
+					n[k] = redact(child)
+				}
+			}
+		case []interface{}:
+			for i, child := range n {
+				n[i] = redact(child)
+			}
+		}
+		return v
+	}
+
+	return func(c *Call) bool {
+		c.Params, c.Result = redact(c.Params), redact(c.Result)
+		return true
+	}
+}
+
+// Recorder is http middleware recording the successful calls served by a JSON-RPC handler, single or batched;
+// it is safe for concurrent use
+type Recorder struct {
+	next   http.Handler
+	redact RedactFunc
+
+	// MaxPerMethod bounds the distinct calls recorded for every method, 5 by default
+	MaxPerMethod int
+
+	mu    sync.Mutex
+	calls map[string][]*Call
+}
+
+// New returns a Recorder wrapping next; redact can be nil if calls need no anonymization
+func New(next http.Handler, redact RedactFunc) *Recorder {
+	return &Recorder{next: next, redact: redact, MaxPerMethod: 5, calls: map[string][]*Call{}}
+}
+
+// ServeHTTP serves the request with the wrapped handler, and records the calls whose response has a result
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+	r.next.ServeHTTP(rec, req)
+
+	if rec.status == http.StatusOK {
+		r.record(body, rec.body.Bytes())
+	}
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(data []byte) (int, error) {
+	rec.body.Write(data)
+	return rec.ResponseWriter.Write(data)
+}
+
+type request struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+type response struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// record pairs requests with their responses by id
+func (r *Recorder) record(reqData, resData []byte) {
+
+	var (
+		reqs []request
+		ress []response
+	)
+
+	if err := json.Unmarshal(reqData, &reqs); err != nil {
+		var req request
+		if err := json.Unmarshal(reqData, &req); err != nil {
+			return
+		}
+		reqs = []request{req}
+	}
+
+	if err := json.Unmarshal(resData, &ress); err != nil {
+		var res response
+		if err := json.Unmarshal(resData, &res); err != nil {
+			return
+		}
+		ress = []response{res}
+	}
+
+	results := map[string]json.RawMessage{}
+	for _, res := range ress {
+		if len(res.ID) > 0 && res.Result != nil && isNull(res.Error) {
+			results[string(res.ID)] = res.Result
+		}
+	}
+
+	for _, req := range reqs {
+		if len(req.ID) == 0 || isNull(req.ID) || req.Method == "" {
+			continue
+		}
+
+		result, ok := results[string(req.ID)]
+		if !ok {
+			continue
+		}
+
+		c := &Call{Method: req.Method}
+
+		if !isNull(req.Params) && decode(req.Params, &c.Params) != nil {
+			continue
+		}
+
+		if decode(result, &c.Result) != nil {
+			continue
+		}
+
+		r.add(c)
+	}
+}
+
+func (r *Recorder) add(c *Call) {
+
+	if r.redact != nil && !r.redact(c) {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := r.calls[c.Method]
+
+	if r.MaxPerMethod > 0 && len(calls) >= r.MaxPerMethod {
+		return
+	}
+
+	for _, other := range calls {
+		if equal(other.Params, c.Params) {
+			return
+		}
+	}
+
+	r.calls[c.Method] = append(calls, c)
+}
+
+// Calls returns the recorded calls, by method name and in the order they were recorded
+func (r *Recorder) Calls() []Call {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	methods := make([]string, 0, len(r.calls))
+	for m := range r.calls {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+
+	var calls []Call
+	for _, m := range methods {
+		for _, c := range r.calls[m] {
+			calls = append(calls, *c)
+		}
+	}
+
+	return calls
+}
+
+// Reset drops the recorded calls
+func (r *Recorder) Reset() {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = map[string][]*Call{}
+}
+
+// Merge adds the recorded calls of the methods of doc to their examples, named "recorded #n", and returns how
+// many were added; calls with the same params as an existing example, and calls of undeclared methods, are skipped
+func (r *Recorder) Merge(doc *openrpc.DocumentSpec1) int {
+
+	calls := r.Calls()
+	added := 0
+
+	// calls are compared with the examples of methods whose references to components are resolved,
+	// and added to the methods of doc
+	methods := map[string]*openrpc.Method{}
+	resolved := map[string]*openrpc.Method{}
+	for _, m := range doc.Methods {
+		if m == nil {
+			continue
+		}
+
+		rm, err := doc.ResolveMethod(m)
+		if err != nil {
+			continue
+		}
+
+		methods[m.Name], resolved[m.Name] = m, rm
+	}
+
+	for _, c := range calls {
+		m, ok := methods[c.Method]
+		if !ok {
+			continue
+		}
+
+		rm := resolved[c.Method]
+		ex := ExamplePairing(rm, c)
+
+		if hasExample(rm, ex) {
+			continue
+		}
+
+		ex.Name = "recorded #" + strconv.Itoa(recorded(rm)+1)
+		m.Examples = append(m.Examples, ex)
+		rm.Examples = append(rm.Examples, ex)
+		added++
+	}
+
+	return added
+}
+
+// ExamplePairing converts a call of m into an example pairing: by-name params are ordered as the params of m,
+// by-position params are named after them
+func ExamplePairing(m *openrpc.Method, c Call) *openrpc.ExamplePairing {
+
+	ex := &openrpc.ExamplePairing{}
+
+	switch params := c.Params.(type) {
+	case []interface{}:
+		for i, v := range params {
+			p := &openrpc.Example{Value: v}
+			if i < len(m.Params) && m.Params[i] != nil {
+				p.Name = m.Params[i].Name
+			}
+			ex.Params = append(ex.Params, p)
+		}
+	case map[string]interface{}:
+		seen := map[string]bool{}
+
+		for _, cd := range m.Params {
+			if cd == nil {
+				continue
+			}
+
+			if v, ok := params[cd.Name]; ok {
+				ex.Params = append(ex.Params, &openrpc.Example{Name: cd.Name, Value: v})
+				seen[cd.Name] = true
+			}
+		}
+
+		// undeclared params are kept, so that validation reports them
+		names := make([]string, 0, len(params))
+		for name := range params {
+			if !seen[name] {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			ex.Params = append(ex.Params, &openrpc.Example{Name: name, Value: params[name]})
+		}
+	}
+
+	ex.Result = &openrpc.Example{Value: c.Result}
+	if m.Result != nil {
+		ex.Result.Name = m.Result.Name
+	}
+
+	return ex
+}
+
+// hasExample reports whether m has an example with the same params as ex
+func hasExample(m *openrpc.Method, ex *openrpc.ExamplePairing) bool {
+
+	values := func(ex *openrpc.ExamplePairing) map[string]interface{} {
+		v := map[string]interface{}{}
+		for i, p := range ex.Params {
+			if p == nil {
+				continue
+			}
+			name := p.Name
+			if name == "" && i < len(m.Params) && m.Params[i] != nil {
+				name = m.Params[i].Name
+			}
+			v[name] = p.Value
+		}
+		return v
+	}
+
+	params := values(ex)
+
+	for _, other := range m.Examples {
+		if other != nil && equal(values(other), params) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// recorded returns the number of examples of m that were recorded
+func recorded(m *openrpc.Method) int {
+
+	n := 0
+	for _, ex := range m.Examples {
+		if ex != nil && strings.HasPrefix(ex.Name, "recorded #") {
+			n++
+		}
+	}
+
+	return n
+}
+
+func decode(data []byte, v *interface{}) error {
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return dec.Decode(v)
+}
+
+func isNull(data json.RawMessage) bool {
+	return len(data) == 0 || string(data) == "null"
+}
+
+func equal(a, b interface{}) bool {
+
+	da, errA := json.Marshal(a)
+	db, errB := json.Marshal(b)
+
+	return errA == nil && errB == nil && bytes.Equal(da, db)
+}
@@ -0,0 +1,173 @@
+package record
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+const document = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "accounts", "version": "1"},
+	"methods": [
+		{
+			"name": "get_account",
+			"params": [{"name": "id", "required": true, "schema": {"type": "integer"}}],
+			"result": {"name": "account", "schema": {"type": "object"}},
+			"examples": [
+				{"name": "first", "params": [{"name": "id", "value": 1}], "result": {"name": "account", "value": {"id": 1}}}
+			]
+		},
+		{
+			"name": "login",
+			"paramStructure": "by-name",
+			"params": [
+				{"name": "user", "required": true, "schema": {"type": "string"}},
+				{"name": "password", "required": true, "schema": {"type": "string"}}
+			],
+			"result": {"name": "session", "schema": {"type": "object"}}
+		}
+	]
+}`
+
+// echo answers every call with an object holding its params, fails fail and ignores notifications
+var echo = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+	var reqs []map[string]json.RawMessage
+
+	data, _ := ioutil.ReadAll(r.Body)
+	batch := strings.HasPrefix(string(data), "[")
+
+	if !batch {
+		data = append(append([]byte("["), data...), ']')
+	}
+	_ = json.Unmarshal(data, &reqs)
+
+	var ress []map[string]interface{}
+
+	for _, req := range reqs {
+		if req["id"] == nil {
+			continue
+		}
+
+		res := map[string]interface{}{"jsonrpc": "2.0", "id": req["id"]}
+		if string(req["method"]) == `"fail"` {
+			res["error"] = map[string]interface{}{"code": -32000, "message": "failed"}
+		} else {
+			res["result"] = map[string]interface{}{"params": req["params"], "token": "secret"}
+		}
+		ress = append(ress, res)
+	}
+
+	if batch {
+		_ = json.NewEncoder(w).Encode(ress)
+	} else {
+		_ = json.NewEncoder(w).Encode(ress[0])
+	}
+})
+
+func TestRecorder(t *testing.T) {
+
+	rec := New(echo, RedactKeys("password", "Token"))
+	srv := httptest.NewServer(rec)
+	defer srv.Close()
+
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"get_account","params":[1]}`,
+		`{"jsonrpc":"2.0","id":2,"method":"get_account","params":[9007199254740993]}`,
+		`{"jsonrpc":"2.0","id":3,"method":"get_account","params":[9007199254740993]}`,
+		`[{"jsonrpc":"2.0","id":"a","method":"login","params":{"password":"hunter2","user":"ann"}},{"jsonrpc":"2.0","method":"get_account","params":[7]},{"jsonrpc":"2.0","id":"b","method":"fail"}]`,
+		`{"jsonrpc":"2.0","id":4,"method":"undeclared"}`,
+	} {
+		res, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	}
+
+	var calls []string
+	for _, c := range rec.Calls() {
+		data, _ := json.Marshal([]interface{}{c.Method, c.Params, c.Result})
+		calls = append(calls, string(data))
+	}
+
+	expected := []string{
+		`["get_account",[1],{"params":[1],"token":"REDACTED"}]`,
+		`["get_account",[9007199254740993],{"params":[9007199254740993],"token":"REDACTED"}]`,
+		`["login",{"password":"REDACTED","user":"ann"},{"params":{"password":"REDACTED","user":"ann"},"token":"REDACTED"}]`,
+		`["undeclared",null,{"params":null,"token":"REDACTED"}]`,
+	}
+
+	if strings.Join(calls, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("error, unexpected calls:\n%s", strings.Join(calls, "\n"))
+	}
+
+	doc, err := openrpc.ParseDocument([]byte(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := rec.Merge(doc); n != 2 {
+		t.Errorf("error, expected 2 examples to be merged, got %d", n)
+	}
+
+	// merging again adds nothing, since the examples have the same params
+	if n := rec.Merge(doc); n != 0 {
+		t.Errorf("error, expected no examples to be merged again, got %d", n)
+	}
+
+	data, err := json.Marshal([]interface{}{doc.Methods[0].Examples, doc.Methods[1].Examples})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[[` +
+		`{"name":"first","params":[{"name":"id","value":1}],"result":{"name":"account","value":{"id":1}}},` +
+		`{"name":"recorded #1","params":[{"name":"id","value":9007199254740993}],"result":{"name":"account","value":{"params":[9007199254740993],"token":"REDACTED"}}}` +
+		`],[` +
+		`{"name":"recorded #1","params":[{"name":"user","value":"ann"},{"name":"password","value":"REDACTED"}],"result":{"name":"session","value":{"params":{"password":"REDACTED","user":"ann"},"token":"REDACTED"}}}` +
+		`]]`
+
+	if string(data) != want {
+		t.Errorf("error, unexpected examples:\n%s", data)
+	}
+
+	if err := doc.Validate(); err != nil {
+		t.Errorf("error, merged examples should be valid: %v", err)
+	}
+}
+
+func TestRecorderLimits(t *testing.T) {
+
+	dropped := 0
+
+	rec := New(echo, func(c *Call) bool {
+		if params, _ := c.Params.([]interface{}); len(params) > 0 && params[0] == json.Number("0") {
+			dropped++
+			return false
+		}
+		return true
+	})
+	rec.MaxPerMethod = 2
+
+	for _, id := range []string{"0", "1", "2", "3"} {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"get_account","params":[`+id+`]}`))
+		rec.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if calls := rec.Calls(); len(calls) != 2 || dropped != 1 {
+		t.Errorf("error, expected 2 calls and 1 dropped, got %v and %d", calls, dropped)
+	}
+
+	rec.Reset()
+
+	if calls := rec.Calls(); len(calls) != 0 {
+		t.Errorf("error, expected no calls after reset, got %v", calls)
+	}
+}
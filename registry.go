@@ -1,77 +1,98 @@
 package openrpc
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"io"
 	"reflect"
-	"strings"
+	"sort"
+	"sync"
 )
 
-// SchemaRegistry is a collection of Schemas
+// SchemaRegistry is a collection of Schemas; it is safe for concurrent use
 type SchemaRegistry struct {
+	// mu guards the registry as a whole, so that a registration or a removal is never observed half done
+	mu             sync.RWMutex
 	reg            *PointerStore
 	pTree          *PointerTree
 	unmarshalFrom  Pointer
 	typeExceptions map[string]reflect.Type
+	ordering       Ordering
+	naming         NamingStrategy
+	collisions     CollisionPolicy
+	typeNames      map[reflect.Type]string
+	namedTypes     map[string]reflect.Type
 }
 
-var (
-	integer = NewSchema()
-	number  = NewSchema()
-	str     = NewSchema()
-	boolean = NewSchema()
-	any     = NewSchema()
-)
+// baseSchemas are the schemas every registry created by NewSchemaRegistry starts with
+var baseSchemas = []struct{ name, data string }{
+	{"integer", integerSchema},
+	{"number", numberSchema},
+	{"string", stringSchema},
+	{"bool", boolSchema},
+	{"anything", anySchema},
+}
 
 // NewSchemaRegistry returns a new JSON schema registry with 5 basic schemas already registered;
 // the Pointer argument is used to select the subtree from which to start marshaling, can be nil
 func NewSchemaRegistry(unmarshalFrom Pointer) (*SchemaRegistry, error) {
 
-	reg := &SchemaRegistry{reg: NewPointerRegistry(), pTree: NewPointerTree(nil), unmarshalFrom: unmarshalFrom, typeExceptions: map[string]reflect.Type{}}
+	reg, _ := NewRegistry(unmarshalFrom)
 
-	reg.pTree.Insert(unmarshalFrom)
+	for _, base := range baseSchemas {
+		sch := NewSchema()
 
-	err := integer.UnmarshalJSON([]byte(integerSchema))
-	err = number.UnmarshalJSON([]byte(numberSchema))
-	err = str.UnmarshalJSON([]byte(stringSchema))
-	err = boolean.UnmarshalJSON([]byte(boolSchema))
-	err = any.UnmarshalJSON([]byte(anySchema))
+		if err := sch.UnmarshalJSON([]byte(base.data)); err != nil {
+			return nil, err
+		}
 
-	if err != nil {
-		return nil, err
+		reg.setSchema(appendRefs(unmarshalFrom, base.name), sch)
 	}
 
-	p, _ := NewPointer(unmarshalFrom.String() + "/integer")
-	reg.reg.Set(p, integer)
-	reg.pTree.Insert(p)
+	return reg, nil
+}
 
-	p, _ = NewPointer(unmarshalFrom.String() + "/number")
-	reg.reg.Set(p, number)
-	reg.pTree.Insert(p)
+func NewRegistry(unmarshalFrom Pointer) (*SchemaRegistry, error) {
+
+	reg := &SchemaRegistry{}
+	reg.init(unmarshalFrom)
 
-	p, _ = NewPointer(unmarshalFrom.String() + "/string")
-	reg.reg.Set(p, str)
-	reg.pTree.Insert(p)
+	return reg, nil
+}
 
-	p, _ = NewPointer(unmarshalFrom.String() + "/bool")
-	reg.reg.Set(p, boolean)
-	reg.pTree.Insert(p)
+func (s *SchemaRegistry) init(unmarshalFrom Pointer) {
 
-	p, _ = NewPointer(unmarshalFrom.String() + "/anything")
-	reg.reg.Set(p, any)
-	reg.pTree.Insert(p)
+	s.reg, s.pTree = NewPointerRegistry(), NewPointerTree(nil)
+	s.unmarshalFrom = unmarshalFrom
+	s.typeExceptions = map[string]reflect.Type{}
 
-	return reg, nil
+	s.pTree.Insert(unmarshalFrom)
 }
 
-func NewRegistry(unmarshalFrom Pointer) (*SchemaRegistry, error) {
+// SetOrdering selects the order in which MarshalJSON writes schemas and their keys; the default is InsertionOrder
+func (s *SchemaRegistry) SetOrdering(order Ordering) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	reg := &SchemaRegistry{reg: NewPointerRegistry(), pTree: NewPointerTree(nil), unmarshalFrom: unmarshalFrom, typeExceptions: map[string]reflect.Type{}}
+	s.ordering = order
+}
 
-	reg.pTree.Insert(unmarshalFrom)
+// SetNamingStrategy selects how schemas of go types are named; the default is ShortNames.
+// It should be called before any type is registered
+func (s *SchemaRegistry) SetNamingStrategy(naming NamingStrategy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return reg, nil
+	s.naming = naming
+}
+
+// SetCollisionPolicy selects what happens when two types get the same name; the default is Disambiguate
+func (s *SchemaRegistry) SetCollisionPolicy(policy CollisionPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.collisions = policy
 }
 
 //AddTypeException signals that the type t should always be represented as a string regardless what its kind is
@@ -81,12 +102,23 @@ func (s *SchemaRegistry) AddTypeException(typ reflect.Type) {
 		typ = typ.Elem()
 	}
 
-	s.typeExceptions[formatTypeName(typ)] = typ
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.typeExceptions[fullTypeName(typ)] = typ
 }
 
 // Register creates a new schema for the provided type, and returns the Pointer by which it is referenced
 func (s *SchemaRegistry) RegisterType(t reflect.Type, registerAsString bool) (Pointer, string, error) {
 
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.registerType(t, registerAsString)
+}
+
+func (s *SchemaRegistry) registerType(t reflect.Type, registerAsString bool) (Pointer, string, error) {
+
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
 	}
@@ -146,18 +178,125 @@ func (s *SchemaRegistry) RegisterType(t reflect.Type, registerAsString bool) (Po
 
 func (s *SchemaRegistry) MarshalJSON() ([]byte, error) {
 
+	var buf bytes.Buffer
+
+	if err := s.Encode(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Encode streams the json object of the schemas registered under the unmarshalFrom pointer to w
+func (s *SchemaRegistry) Encode(w io.Writer) error {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	tree := s.pTree.Find(s.unmarshalFrom)
 	if tree == nil {
+		return errors.New("unmarshalFrom pointer points to nil tree")
+	}
+
+	return tree.Encode(w, s.reg, s.ordering)
+}
+
+// Diff compares the schemas registered in two registries, starting from their unmarshalFrom pointers;
+// registrations running concurrently may or may not be taken into account
+func (s *SchemaRegistry) Diff(other *SchemaRegistry) ([]PathDiff, error) {
+
+	tree := s.pTree.Find(s.unmarshalFrom)
+	otherTree := other.pTree.Find(other.unmarshalFrom)
+
+	if tree == nil || otherTree == nil {
 		return nil, errors.New("unmarshalFrom pointer points to nil tree")
 	}
 
-	j, err := tree.ResolvePointers(s.reg)
+	return tree.Diff(s.reg, otherTree, other.reg)
+}
 
-	if err != nil {
-		return nil, err
+// Equal reports whether two registries hold the same schemas under the same paths
+func (s *SchemaRegistry) Equal(other *SchemaRegistry) (bool, error) {
+
+	diffs, err := s.Diff(other)
+
+	return len(diffs) == 0, err
+}
+
+// Schemas returns the pointers of the schemas registered under the unmarshalFrom pointer, in the order they are marshaled
+func (s *SchemaRegistry) Schemas() []Pointer {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tree := s.pTree.Find(s.unmarshalFrom)
+	if tree == nil {
+		return nil
 	}
 
-	return json.Marshal(j)
+	var ptrs []Pointer
+
+	for _, ptr := range tree.Pointers() {
+		if s.isRegistered(ptr) {
+			ptrs = append(ptrs, ptr)
+		}
+	}
+
+	if s.ordering == Alphabetical {
+		sort.Slice(ptrs, func(i, j int) bool { return ptrs[i].String() < ptrs[j].String() })
+	}
+
+	return ptrs
+}
+
+// Schema returns the schema registered under ptr
+func (s *SchemaRegistry) Schema(ptr Pointer) (Schema, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.reg.Get(ptr)
+}
+
+// Resolve returns the decoded json value a pointer into the registered schemas points to,
+// e.g. /components/schemas/pkg.Block/properties/hash
+func (s *SchemaRegistry) Resolve(ptr Pointer) (interface{}, error) {
+	return s.reg.Resolve(ptr)
+}
+
+// Replace replaces the schema registered under ptr, returning an error if there is none
+func (s *SchemaRegistry) Replace(ptr Pointer, sch Schema) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.isRegistered(ptr) {
+		return errors.New("no schema registered under " + ptr.String())
+	}
+
+	s.reg.Set(ptr, sch)
+
+	return nil
+}
+
+// Remove drops the schema registered under ptr, along with any schema nested under it, and reports whether
+// anything was removed; references to the removed schemas from other schemas are left untouched
+func (s *SchemaRegistry) Remove(ptr Pointer) bool {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := s.pTree.Remove(ptr)
+	if removed == nil {
+		return s.reg.Delete(ptr)
+	}
+
+	s.reg.Delete(ptr)
+
+	for _, p := range removed.Pointers() {
+		s.reg.Delete(p)
+	}
+
+	return true
 }
 
 func (s *SchemaRegistry) String() string {
@@ -170,26 +309,22 @@ func (s *SchemaRegistry) String() string {
 //IsTypeException reports whether type t is an exception
 func (s *SchemaRegistry) isTypeException(typ reflect.Type) bool {
 
-	for _, tt := range s.typeExceptions {
-		if formatTypeName(typ) == formatTypeName(tt) {
-			return true
-		}
-	}
+	_, ok := s.typeExceptions[fullTypeName(typ)]
 
-	return false
+	return ok
 }
 
 func (s *SchemaRegistry) handleSlice(t reflect.Type) (pointer Pointer, schema Schema, name string, err error) {
 	var (
-		tPtr  Pointer
-		tSch  Schema
-		tName string
-		tErr  error
+		tPtr Pointer
+		tSch Schema
+		tErr error
 	)
 
+	sliceType := t
 	t = t.Elem()
 
-	tPtr, tSch, tName, tErr = s.createSchema(t)
+	tPtr, tSch, _, tErr = s.createSchema(t)
 	if tErr != nil {
 		return nil, nil, "", tErr
 	}
@@ -203,8 +338,8 @@ func (s *SchemaRegistry) handleSlice(t reflect.Type) (pointer Pointer, schema Sc
 		"items": tPtr,
 	}
 
-	if t.Kind() == reflect.Array {
-		m["maxItems"] = t.Len()
+	if sliceType.Kind() == reflect.Array {
+		m["maxItems"] = sliceType.Len()
 	}
 
 	bytes, err := json.Marshal(m)
@@ -219,21 +354,23 @@ func (s *SchemaRegistry) handleSlice(t reflect.Type) (pointer Pointer, schema Sc
 		return nil, nil, "", err
 	}
 
-	slicePtr, err := NewPointer(fmt.Sprintf("%s[]", tPtr))
+	sliceName, err := s.nameOf(sliceType)
+	if err != nil {
+		return nil, nil, "", err
+	}
 
-	return slicePtr, sliceSchema, tName + "[]", nil
+	return appendRefs(s.unmarshalFrom, sliceName), sliceSchema, sliceName, nil
 }
 func (s *SchemaRegistry) handleMap(t reflect.Type) (Pointer, Schema, string, error) {
 	var (
-		ePtr  Pointer
-		eSch  Schema
-		eName string
-		eErr  error
+		ePtr Pointer
+		eSch Schema
+		eErr error
 	)
 
 	e := t.Elem()
 
-	ePtr, eSch, eName, eErr = s.createSchema(e)
+	ePtr, eSch, _, eErr = s.createSchema(e)
 	if eErr != nil {
 		return nil, nil, "", eErr
 	}
@@ -263,10 +400,12 @@ func (s *SchemaRegistry) handleMap(t reflect.Type) (Pointer, Schema, string, err
 		return nil, nil, "", err
 	}
 
-	mapName := fmt.Sprintf("Object[%s]", eName)
-	mapPtr, err := NewPointer(fmt.Sprintf("%s/%s", s.unmarshalFrom.String(), mapName))
+	mapName, err := s.nameOf(t)
+	if err != nil {
+		return nil, nil, "", err
+	}
 
-	return mapPtr, mapSchema, mapName, nil
+	return appendRefs(s.unmarshalFrom, mapName), mapSchema, mapName, nil
 }
 
 // TODO
@@ -307,47 +446,10 @@ func (s *SchemaRegistry) createSchema(t reflect.Type) (pointer Pointer, schema S
 		return nil, nil, "", err
 	}
 
-	name = formatTypeName(t)
-
-	p, err := NewPointer(s.unmarshalFrom.String() + "/" + name)
+	name, err = s.nameOf(t)
 	if err != nil {
 		return nil, nil, "", err
 	}
 
-	return p, sch, name, nil
-}
-
-func formatTypeName(t reflect.Type) string {
-
-	name := t.Name()
-
-	if name == "" {
-		idx := strings.LastIndexFunc(t.String(), func(r rune) bool {
-			return r == ']' || r == '*'
-		})
-		name = t.String()[idx+1:]
-	}
-
-	switch t.Kind() {
-	case reflect.Map:
-		e := t.Elem()
-
-		if e.Kind() == reflect.Interface {
-			return "Object[anything]"
-		}
-
-		return "object[" + name + "]"
-	case reflect.Ptr:
-		return formatTypeName(t.Elem())
-	case reflect.Interface:
-		return "anything"
-	default:
-		s := strings.Split(t.PkgPath(), "/")
-		if l := len(s); l > 0 {
-			if pkgName := s[l-1]; pkgName != "" {
-				return pkgName + "." + name
-			}
-		}
-		return name
-	}
+	return appendRefs(s.unmarshalFrom, name), sch, name, nil
 }
@@ -0,0 +1,382 @@
+package openrpc
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type registryTestStruct struct {
+	Name  string
+	Count int
+}
+
+func TestRegistryDiff(t *testing.T) {
+
+	root, _ := NewPointer("/components/schemas")
+
+	r1, err := NewSchemaRegistry(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r2, err := NewSchemaRegistry(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if equal, err := r1.Equal(r2); err != nil || !equal {
+		t.Fatalf("error, new registries should be equal: %v", err)
+	}
+
+	if _, _, err := r2.RegisterType(reflect.TypeOf(registryTestStruct{}), false); err != nil {
+		t.Fatal(err)
+	}
+
+	diffs, err := r1.Diff(r2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(diffs) != 1 || diffs[0].String() != "added /g0penrpc.registryTestStruct" {
+		t.Errorf("error, got %v instead of the added struct", diffs)
+	}
+}
+
+type orderingTestStruct struct {
+	Zeta  string
+	Alpha string
+	Mu    string
+}
+
+func TestRegistryOrdering(t *testing.T) {
+
+	root, _ := NewPointer("/components/schemas")
+
+	reg, err := NewRegistry(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.UnmarshalJSON([]byte(`{"b": {"type": "string"}, "a": {"type": "integer"}}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := reg.RegisterType(reflect.TypeOf(orderingTestStruct{}), false); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("insertionOrder", func(t *testing.T) {
+
+		data, err := reg.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := `{"b":{"type":"string"},"a":{"type":"integer"},"g0penrpc.orderingTestStruct":{"properties":{"Zeta":{"type":"string","pattern":"(.*)"},"Alpha":{"type":"string","pattern":"(.*)"},"Mu":{"type":"string","pattern":"(.*)"}},"type":"object"}}`
+
+		if string(data) != want {
+			t.Errorf("error, got \n %s \n instead of \n %s", data, want)
+		}
+	})
+
+	t.Run("alphabetical", func(t *testing.T) {
+
+		reg.SetOrdering(Alphabetical)
+
+		data, err := reg.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := `{"a":{"type":"integer"},"b":{"type":"string"},"g0penrpc.orderingTestStruct":{"properties":{"Alpha":{"pattern":"(.*)","type":"string"},"Mu":{"pattern":"(.*)","type":"string"},"Zeta":{"pattern":"(.*)","type":"string"}},"type":"object"}}`
+
+		if string(data) != want {
+			t.Errorf("error, got \n %s \n instead of \n %s", data, want)
+		}
+	})
+}
+
+func TestRegistryTypeNameRoundTrip(t *testing.T) {
+
+	root, _ := NewPointer("/components/schemas")
+
+	reg, err := NewSchemaRegistry(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for typ, uri := range map[reflect.Type]string{
+		reflect.TypeOf([]int{}):          "#/components/schemas/int%5B%5D",
+		reflect.TypeOf(map[string]int{}): "#/components/schemas/Object%5Bint%5D",
+	} {
+		ptr, name, err := reg.RegisterType(typ, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ptr.String() != "/components/schemas/"+name {
+			t.Fatalf("unexpected pointer %s for %s", ptr, name)
+		}
+
+		data, err := ptr.MarshalJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(data) != `{"$ref":"`+uri+`"}` {
+			t.Fatalf("unexpected reference %s to %s", data, name)
+		}
+
+		parsed, err := NewPointer(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if node := reg.pTree.Find(parsed); node == nil || !refsEqual(node.ptr, ptr) {
+			t.Fatalf("%s not found in the pointer tree", uri)
+		}
+
+		if _, ok := reg.reg.Get(parsed); !ok {
+			t.Fatalf("%s not found in the pointer store", uri)
+		}
+
+		res, err := NewRefResolver(&DocumentSpec1{Components: &Components{Schemas: reg}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := res.Resolve(uri); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRegistryNaming(t *testing.T) {
+
+	root, _ := NewPointer("/components/schemas")
+
+	reg, err := NewRegistry(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg.SetNamingStrategy(Naming{
+		Type:  fullTypeName,
+		Slice: func(elem string) string { return "ArrayOf" + elem },
+		Map:   func(elem string) string { return "MapOf" + elem },
+	})
+
+	for typ, expected := range map[reflect.Type]string{
+		reflect.TypeOf(registryTestStruct{}):               "github.com/octanolabs/g0penrpc.registryTestStruct",
+		reflect.TypeOf([]int{}):                            "ArrayOfint",
+		reflect.TypeOf(map[string][]*orderingTestStruct{}): "MapOfArrayOfgithub.com/octanolabs/g0penrpc.orderingTestStruct",
+	} {
+		ptr, name, err := reg.RegisterType(typ, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if name != expected || ptr.Refs()[len(ptr.Refs())-1] != expected {
+			t.Errorf("error, %s named %s (%s) instead of %s", typ, name, ptr, expected)
+		}
+	}
+}
+
+func TestRegistryNameCollisions(t *testing.T) {
+
+	root, _ := NewPointer("/components/schemas")
+
+	sameName := NamingFunc(func(reflect.Type) string { return "types.Block" })
+
+	reg, _ := NewRegistry(root)
+	reg.SetNamingStrategy(sameName)
+
+	for i, typ := range []reflect.Type{reflect.TypeOf(registryTestStruct{}), reflect.TypeOf(orderingTestStruct{}), reflect.TypeOf(registryTestStruct{})} {
+		_, name, err := reg.RegisterType(typ, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := []string{"types.Block", "types.Block_2", "types.Block"}[i]; name != expected {
+			t.Errorf("error, %s named %s instead of %s", typ, name, expected)
+		}
+	}
+
+	if names := reg.pTree.Find(root).keys; len(names) != 2 {
+		t.Errorf("error, expected 2 schemas, got %v", names)
+	}
+
+	reg, _ = NewRegistry(root)
+	reg.SetNamingStrategy(sameName)
+	reg.SetCollisionPolicy(FailOnCollision)
+
+	if _, _, err := reg.RegisterType(reflect.TypeOf(registryTestStruct{}), false); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := reg.RegisterType(reflect.TypeOf(orderingTestStruct{}), false); err == nil {
+		t.Error("error, expected a collision error")
+	}
+}
+
+func TestRegistryPostProcessing(t *testing.T) {
+
+	root, _ := NewPointer("/components/schemas")
+
+	reg, err := NewSchemaRegistry(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	internal, _, err := reg.RegisterType(reflect.TypeOf(registryTestStruct{}), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reg.Schemas()) != 6 {
+		t.Fatalf("error, expected 6 schemas, got %v", reg.Schemas())
+	}
+
+	if !reg.Remove(internal) || reg.Remove(internal) {
+		t.Error("error, a schema should be removed exactly once")
+	}
+
+	str, _ := NewPointer("/components/schemas/string")
+
+	replacement := NewSchema()
+	if err := replacement.UnmarshalJSON([]byte(`{"type":"string","minLength":1}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Replace(str, replacement); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reg.Replace(internal, replacement); err == nil {
+		t.Error("error, replaced a removed schema")
+	}
+
+	reg.SetOrdering(Alphabetical)
+
+	var names []string
+	for _, p := range reg.Schemas() {
+		names = append(names, p.Refs()[len(p.Refs())-1])
+	}
+
+	if strings.Join(names, ",") != "anything,bool,integer,number,string" {
+		t.Errorf("error, unexpected schemas %v", names)
+	}
+
+	data, err := reg.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `"string":{"minLength":1,"type":"string"}`) || strings.Contains(string(data), "registryTestStruct") {
+		t.Errorf("error, unexpected registry %s", data)
+	}
+}
+
+type raceTestA struct{ A string }
+type raceTestB struct{ B string }
+type raceTestC struct{ C string }
+
+// TestRegistryConcurrency registers, marshals and post-processes schemas from many goroutines;
+// run it with go test -race
+func TestRegistryConcurrency(t *testing.T) {
+
+	root, _ := NewPointer("/components/schemas")
+
+	reg, err := NewSchemaRegistry(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types := []reflect.Type{
+		reflect.TypeOf(raceTestA{}),
+		reflect.TypeOf(&raceTestB{}),
+		reflect.TypeOf([]raceTestC{}),
+		reflect.TypeOf(map[string]raceTestA{}),
+		reflect.TypeOf(0),
+	}
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			if _, _, err := reg.RegisterType(types[i%len(types)], i%7 == 0); err != nil {
+				t.Error(err)
+			}
+
+			if _, err := reg.MarshalJSON(); err != nil {
+				t.Error(err)
+			}
+
+			for _, ptr := range reg.Schemas() {
+				reg.Schema(ptr)
+			}
+
+			if i%5 == 0 {
+				reg.Remove(appendRefs(root, "g0penrpc.raceTestA"))
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	if _, err := reg.MarshalJSON(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRegistriesDoNotShareSchemas checks that changing a base schema of a registry leaves other registries untouched
+func TestRegistriesDoNotShareSchemas(t *testing.T) {
+
+	root, _ := NewPointer("/components/schemas")
+
+	r1, _ := NewSchemaRegistry(root)
+	r2, _ := NewSchemaRegistry(root)
+
+	ptr := appendRefs(root, "string")
+
+	s1, _ := r1.Schema(ptr)
+	s2, _ := r2.Schema(ptr)
+
+	if s1 == s2 {
+		t.Fatal("error, registries share the same string schema instance")
+	}
+
+	var wg sync.WaitGroup
+
+	for _, reg := range []*SchemaRegistry{r1, r2} {
+		wg.Add(1)
+
+		go func(reg *SchemaRegistry) {
+			defer wg.Done()
+
+			if _, err := NewSchemaRegistry(root); err != nil {
+				t.Error(err)
+			}
+
+			if _, err := reg.MarshalJSON(); err != nil {
+				t.Error(err)
+			}
+		}(reg)
+	}
+
+	wg.Wait()
+
+	if err := s1.UnmarshalJSON([]byte(`{"type":"string","minLength":1}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if equal, err := r1.Equal(r2); err != nil || equal {
+		t.Errorf("error, changing a schema of one registry should not affect another: %v", err)
+	}
+}
@@ -0,0 +1,184 @@
+package render
+
+import (
+	"bytes"
+	"html/template"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+// HTMLTemplate is the default template of HTML documentation, a single page with no external resources
+const HTMLTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Info.Title}} {{.Info.Version}}</title>
+<style>{{template "style"}}</style>
+</head>
+<body>
+<nav>
+{{- template "index" .}}
+</nav>
+<main>
+<header>
+<h1>{{.Info.Title}} <small>{{.Info.Version}}</small></h1>
+{{- with .Info.Description}}
+<p>{{.}}</p>
+{{- end}}
+</header>
+{{- template "servers" .Servers}}
+<section id="methods">
+<h2>Methods</h2>
+{{- range .Methods}}
+{{template "method" .}}
+{{- end}}
+</section>
+{{- if .Schemas}}
+<section id="schemas">
+<h2>Schemas</h2>
+{{- range .Schemas}}
+{{template "schema" .}}
+{{- end}}
+</section>
+{{- end}}
+</main>
+</body>
+</html>
+{{/* named templates */ -}}
+
+{{define "style" -}}
+body { display: flex; margin: 0; font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; line-height: 1.5; color: #222; }
+nav { width: 16rem; flex-shrink: 0; height: 100vh; position: sticky; top: 0; overflow-y: auto; padding: 1rem; background: #f6f8fa; box-sizing: border-box; }
+nav ul { list-style: none; padding-left: 0; }
+nav h3 { font-size: 0.9rem; text-transform: uppercase; color: #666; }
+main { flex-grow: 1; max-width: 60rem; padding: 1rem 2rem; }
+article { border-top: 1px solid #ddd; padding-top: 1rem; margin-top: 2rem; }
+table { border-collapse: collapse; width: 100%; margin: 0.5rem 0; }
+th, td { border: 1px solid #ddd; padding: 0.3rem 0.6rem; text-align: left; vertical-align: top; }
+code, pre { font-family: SFMono-Regular, Consolas, monospace; font-size: 0.9em; }
+pre { background: #f6f8fa; padding: 0.6rem; overflow-x: auto; }
+.badge { font-size: 0.75rem; padding: 0.1rem 0.4rem; border-radius: 0.3rem; vertical-align: middle; }
+.deprecated { background: #fbe9e7; color: #b71c1c; }
+{{- end}}
+
+{{- define "servers"}}{{if .}}
+<section id="servers">
+<h2>Servers</h2>
+<ul>
+{{- range .}}
+<li><strong>{{.Name}}</strong>: <code>{{.URL}}</code>{{with .Summary}} — {{.}}{{end}}</li>
+{{- end}}
+</ul>
+</section>
+{{- end}}{{end}}
+
+{{- define "index"}}
+{{- range .Groups}}
+<h3 id="{{.Anchor}}">{{if .Tag.Name}}{{.Tag.Name}}{{else}}Other{{end}}</h3>
+{{- with .Tag.Description}}
+<p>{{.}}</p>
+{{- end}}
+<ul>
+{{- range .Methods}}
+<li><a href="#{{.Anchor}}">{{.Name}}</a>{{if .Deprecated}} {{template "deprecated"}}{{end}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}
+
+{{- define "deprecated"}}<span class="badge deprecated">deprecated</span>{{end}}
+
+{{- define "method"}}<article id="{{.Anchor}}">
+<h3>{{.Name}}{{if .Deprecated}} {{template "deprecated"}}{{end}}</h3>
+{{- with .Summary}}
+<p><strong>{{.}}</strong></p>
+{{- end}}
+{{- with .Description}}
+<p>{{.}}</p>
+{{- end}}
+<h4>Params</h4>
+{{if .Params}}{{template "fields" .Params}}{{else}}<p>None</p>{{end}}
+<h4>Result</h4>
+{{if .Result}}{{template "fields" .Result}}{{else}}<p>None</p>{{end}}
+{{- template "errors" .Errors}}
+{{- template "examples" .Examples}}
+</article>{{end}}
+
+{{- define "fields"}}<table>
+<thead><tr><th>Name</th><th>Type</th><th>Required</th><th>Description</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr><td style="padding-left: {{.Depth}}.6rem"><code>{{.Name}}</code></td><td>{{if .Ref}}<a href="#{{.Ref}}">{{.Type}}</a>{{else}}{{.Type}}{{end}}{{with .Enum}} ({{join . ", "}}){{end}}</td><td>{{if .Required}}yes{{else}}no{{end}}</td><td>{{if .Deprecated}}{{template "deprecated"}} {{end}}{{.Description}}</td></tr>
+{{- end}}
+</tbody>
+</table>{{end}}
+
+{{- define "errors"}}{{if .}}
+<h4>Errors</h4>
+<table>
+<thead><tr><th>Code</th><th>Message</th><th>Data</th></tr></thead>
+<tbody>
+{{- range .}}
+<tr><td>{{.Code}}</td><td>{{.Message}}</td><td>{{with .Data}}<code>{{json .}}</code>{{end}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+{{- end}}{{end}}
+
+{{- define "examples"}}{{if .}}
+<h4>Examples</h4>
+{{- range .}}
+<h5>{{if .Name}}{{.Name}}{{else}}Example{{end}}{{with .Summary}}: {{.}}{{end}}</h5>
+{{- with .Description}}
+<p>{{.}}</p>
+{{- end}}
+{{- with .Params}}
+<p>Params:</p>
+<pre><code>{{.}}</code></pre>
+{{- end}}
+{{- with .Result}}
+<p>Result:</p>
+<pre><code>{{.}}</code></pre>
+{{- end}}
+{{- end}}
+{{- end}}{{end}}
+
+{{- define "schema"}}<article id="{{.Anchor}}">
+<h3>{{.Name}}</h3>
+<p>Type: {{.Type}}</p>
+{{- with .Description}}
+<p>{{.}}</p>
+{{- end}}
+{{- with .Fields}}
+{{template "fields" .}}
+{{- end}}
+</article>{{end}}`
+
+// HTML renders the documentation of doc as a static single page HTML site
+func HTML(doc *openrpc.DocumentSpec1, opts Options) ([]byte, error) {
+
+	page, err := NewPage(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("html").Funcs(template.FuncMap(funcs)).Parse(HTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Template != "" {
+		if tmpl, err = tmpl.Parse(opts.Template); err != nil {
+			return nil, err
+		}
+	}
+
+	var b bytes.Buffer
+
+	if err := tmpl.Execute(&b, page); err != nil {
+		return nil, err
+	}
+
+	return append(bytes.TrimSpace(b.Bytes()), '\n'), nil
+}
@@ -0,0 +1,202 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"text/template"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+)
+
+// Options customizes the rendered documentation
+type Options struct {
+	// Template is parsed after the default template of the format, so it can either replace the whole page
+	// or redefine some of the named templates it is made of: servers, index, method, fields, errors, examples and schema.
+	// Templates are executed with a *Page
+	Template string
+}
+
+// MarkdownTemplate is the default template of Markdown documentation
+const MarkdownTemplate = `# {{.Info.Title}}
+
+Version {{.Info.Version}}
+{{- with .Info.Description}}
+
+{{.}}
+{{- end}}
+{{- template "servers" .Servers}}
+{{- template "index" .}}
+{{- range .Methods}}
+{{- template "method" .}}
+{{- end}}
+{{- if .Schemas}}
+
+## Schemas
+{{- range .Schemas}}
+{{- template "schema" .}}
+{{- end}}
+{{- end}}
+{{/* named templates */ -}}
+
+{{define "servers"}}{{if .}}
+
+## Servers
+{{range .}}
+- **{{.Name}}**: ` + "`{{.URL}}`" + `{{with .Summary}} — {{.}}{{end}}
+{{- end}}
+{{- end}}{{end}}
+
+{{- define "index"}}
+
+## Methods
+{{- range .Groups}}
+
+### {{if .Tag.Name}}{{.Tag.Name}}{{else}}Other{{end}}
+{{- with .Tag.Description}}
+
+{{.}}
+{{- end}}
+{{range .Methods}}
+- [{{.Name}}](#{{.Anchor}}){{if .Deprecated}} **deprecated**{{end}}{{with .Summary}} — {{.}}{{end}}
+{{- end}}
+{{- end}}
+{{- end}}
+
+{{- define "method"}}
+
+<a id="{{.Anchor}}"></a>
+### {{.Name}}{{if .Deprecated}} **deprecated**{{end}}
+{{- with .Summary}}
+
+{{.}}
+{{- end}}
+{{- with .Description}}
+
+{{.}}
+{{- end}}
+
+#### Params
+
+{{if .Params}}{{template "fields" .Params}}{{else}}None{{end}}
+
+#### Result
+
+{{if .Result}}{{template "fields" .Result}}{{else}}None{{end}}
+{{- template "errors" .Errors}}
+{{- template "examples" .Examples}}
+{{- end}}
+
+{{- define "fields"}}| Name | Type | Required | Description |
+| --- | --- | --- | --- |
+{{- range .}}
+| {{if .Depth}}{{indent .Depth}}{{end}}` + "`{{.Name}}`" + ` | {{if .Ref}}[{{cell .Type}}](#{{.Ref}}){{else}}{{cell .Type}}{{end}}{{with .Enum}} ({{cell (join . ", ")}}){{end}} | {{if .Required}}yes{{else}}no{{end}} | {{if .Deprecated}}**deprecated** {{end}}{{cell .Description}} |
+{{- end}}
+{{- end}}
+
+{{- define "errors"}}{{if .}}
+
+#### Errors
+
+| Code | Message | Data |
+| --- | --- | --- |
+{{- range .}}
+| {{.Code}} | {{cell .Message}} | {{with .Data}}` + "`{{cell (json .)}}`" + `{{end}} |
+{{- end}}
+{{- end}}{{end}}
+
+{{- define "examples"}}{{if .}}
+
+#### Examples
+{{- range .}}
+
+**{{if .Name}}{{.Name}}{{else}}Example{{end}}**{{with .Summary}}: {{.}}{{end}}
+{{- with .Description}}
+
+{{.}}
+{{- end}}
+{{- with .Params}}
+
+Params:
+
+` + "```json" + `
+{{.}}
+` + "```" + `
+{{- end}}
+{{- with .Result}}
+
+Result:
+
+` + "```json" + `
+{{.}}
+` + "```" + `
+{{- end}}
+{{- end}}
+{{- end}}{{end}}
+
+{{- define "schema"}}
+
+<a id="{{.Anchor}}"></a>
+### {{.Name}}
+
+Type: {{.Type}}
+{{- with .Description}}
+
+{{.}}
+{{- end}}
+{{- with .Fields}}
+
+{{template "fields" .}}
+{{- end}}
+{{- end}}`
+
+// Markdown renders the documentation of doc as Markdown
+func Markdown(doc *openrpc.DocumentSpec1, opts Options) ([]byte, error) {
+
+	page, err := NewPage(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New("markdown").Funcs(template.FuncMap(funcs)).Parse(MarkdownTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Template != "" {
+		if tmpl, err = tmpl.Parse(opts.Template); err != nil {
+			return nil, err
+		}
+	}
+
+	var b bytes.Buffer
+
+	if err := tmpl.Execute(&b, page); err != nil {
+		return nil, err
+	}
+
+	return append(bytes.TrimSpace(b.Bytes()), '\n'), nil
+}
+
+// funcs are available to both Markdown and HTML templates
+var funcs = map[string]interface{}{
+	"join": strings.Join,
+	"json": jsonValue,
+	"cell": func(s string) string {
+		s = strings.Replace(s, "|", "\\|", -1)
+		return strings.Replace(strings.TrimSpace(s), "\n", "<br>", -1)
+	},
+	"indent": func(depth int) string {
+		return strings.Repeat("&nbsp;&nbsp;", depth)
+	},
+}
+
+func jsonValue(v interface{}) string {
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
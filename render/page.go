@@ -0,0 +1,465 @@
+// Package render turns openrpc documents into Markdown and static HTML documentation
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	"github.com/octanolabs/g0penrpc/internal/docutil"
+)
+
+// maxDepth limits how deep the properties of inline objects are listed in field tables
+const maxDepth = 4
+
+// Page is the data documentation templates are executed with
+type Page struct {
+	Doc     *openrpc.DocumentSpec1
+	Info    *openrpc.Info
+	Servers []*openrpc.Server
+	// Groups lists the methods by tag, as grouped by DocumentSpec1.MethodsByTag
+	Groups  []*Group
+	Methods []*Method
+	Schemas []*Schema
+}
+
+// Group is a tag and the methods tagged with it
+type Group struct {
+	Tag     openrpc.Tag
+	Anchor  string
+	Methods []*Method
+}
+
+// Method is a method with its params, result and examples prepared for rendering
+type Method struct {
+	*openrpc.Method
+	Anchor string
+	// Params lists the params, followed by the properties of inline object params
+	Params []*Field
+	Result []*Field
+	// Errors lists the errors of the method, with references to components/errors resolved
+	Errors   []openrpc.Error
+	Examples []*Example
+}
+
+// Field is a row of a params, result or properties table
+type Field struct {
+	// Name is the name of the param or property; properties of inline objects are prefixed with their parent, e.g. block.hash
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+	Deprecated  bool
+	// Ref is the anchor of the component schema the field refers to, if any
+	Ref string
+	// Enum lists the json encoded values the field accepts
+	Enum []string
+	// Depth is 0 for params and results and is incremented for every level of nested properties
+	Depth int
+}
+
+// Example is an example pairing with its values encoded as indented json
+type Example struct {
+	Name        string
+	Summary     string
+	Description string
+	Params      string
+	Result      string
+}
+
+// Schema is a component schema and its properties
+type Schema struct {
+	Name        string
+	Anchor      string
+	Type        string
+	Description string
+	Fields      []*Field
+}
+
+// NewPage resolves the schemas of doc and prepares it for rendering
+func NewPage(doc *openrpc.DocumentSpec1) (*Page, error) {
+
+	res, err := openrpc.NewRefResolver(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Page{Doc: doc, Info: doc.Info, Servers: doc.Servers}
+	b := &pageBuilder{res: res, anchors: map[string]bool{}}
+
+	schemas := docutil.ComponentSchemas(res.Root())
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// schema anchors are allocated first, so that fields can link to them
+	b.schemaAnchors = map[string]string{}
+	for _, name := range names {
+		b.schemaAnchors[name] = b.anchor("schema-" + name)
+	}
+
+	methods := map[*openrpc.Method]*Method{}
+
+	resolved, err := doc.ResolveMethods()
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range doc.Methods {
+		if m == nil {
+			continue
+		}
+
+		method, err := b.method(resolved[i])
+		if err != nil {
+			return nil, errors.New("method " + m.Name + ": " + err.Error())
+		}
+
+		methods[m] = method
+		p.Methods = append(p.Methods, method)
+	}
+
+	groups, err := doc.MethodsByTag()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tg := range groups {
+		anchor := "tag-" + tg.Tag.Name
+		if tg.Tag.Name == "" {
+			anchor = "tag-other"
+		}
+
+		g := &Group{Tag: tg.Tag, Anchor: b.anchor(anchor)}
+
+		for _, m := range tg.Methods {
+			g.Methods = append(g.Methods, methods[m])
+		}
+
+		p.Groups = append(p.Groups, g)
+	}
+
+	for _, name := range names {
+		sch, _ := schemas[name].(map[string]interface{})
+
+		s := &Schema{Name: name, Anchor: b.schemaAnchors[name], Description: docutil.Description(sch)}
+
+		field := b.field("", sch, 0)
+		s.Type = field.Type
+
+		s.Fields = b.properties("", sch, 0)
+
+		p.Schemas = append(p.Schemas, s)
+	}
+
+	return p, nil
+}
+
+type pageBuilder struct {
+	res           *openrpc.RefResolver
+	anchors       map[string]bool
+	schemaAnchors map[string]string
+}
+
+func (b *pageBuilder) method(m *openrpc.Method) (*Method, error) {
+
+	method := &Method{Method: m, Anchor: b.anchor(m.Name)}
+
+	for _, cd := range m.Params {
+		fields, err := b.contentDescriptor(cd)
+		if err != nil {
+			return nil, err
+		}
+
+		method.Params = append(method.Params, fields...)
+	}
+
+	if m.Result != nil {
+		fields, err := b.contentDescriptor(m.Result)
+		if err != nil {
+			return nil, err
+		}
+
+		method.Result = fields
+	}
+
+	for _, e := range m.Errors {
+		resolved, err := b.res.Error(e)
+		if err != nil {
+			return nil, err
+		}
+
+		method.Errors = append(method.Errors, resolved)
+	}
+
+	for _, ex := range m.Examples {
+		if ex == nil {
+			continue
+		}
+
+		method.Examples = append(method.Examples, example(m, ex))
+	}
+
+	return method, nil
+}
+
+// contentDescriptor returns the row of a content descriptor, followed by the rows of its properties
+func (b *pageBuilder) contentDescriptor(cd *openrpc.ContentDescriptor) ([]*Field, error) {
+
+	sch, err := b.res.Schema(cd)
+	if err != nil {
+		return nil, err
+	}
+
+	f := b.field(cd.Name, sch, 0)
+	f.Required, f.Deprecated = cd.Required, cd.Deprecated
+
+	if cd.Description != "" {
+		f.Description = cd.Description
+	} else if cd.Summary != "" {
+		f.Description = cd.Summary
+	}
+
+	return append([]*Field{f}, b.properties(cd.Name, sch, 1)...), nil
+}
+
+// field describes a schema; references to component schemas are described by name and linked
+func (b *pageBuilder) field(name string, sch interface{}, depth int) *Field {
+
+	f := &Field{Name: name, Depth: depth, Description: docutil.Description(sch)}
+
+	m, _ := sch.(map[string]interface{})
+
+	if ref, ok := openrpc.RefOf(m); ok {
+		if component, ok := b.component(ref); ok {
+			f.Type, f.Ref = component, b.schemaAnchors[component]
+
+			if f.Description == "" {
+				if target, err := b.res.Resolve(ref); err == nil {
+					f.Description = docutil.Description(target)
+				}
+			}
+
+			return f
+		}
+
+		target, err := b.res.Deref(m)
+		if err != nil {
+			f.Type = "unknown"
+			return f
+		}
+
+		m, _ = target.(map[string]interface{})
+	}
+
+	if enum, ok := m["enum"].([]interface{}); ok {
+		for _, v := range enum {
+			data, _ := json.Marshal(v)
+			f.Enum = append(f.Enum, string(data))
+		}
+	}
+
+	f.Type = b.typeOf(m)
+
+	// arrays of component schemas link to their items
+	if item, ok := m["items"].(map[string]interface{}); ok {
+		if ref, ok := openrpc.RefOf(item); ok {
+			if component, ok := b.component(ref); ok {
+				f.Ref = b.schemaAnchors[component]
+			}
+		}
+	}
+
+	return f
+}
+
+// typeOf describes the type of a schema, e.g. string, Pet[], integer or null, object
+func (b *pageBuilder) typeOf(m map[string]interface{}) string {
+
+	if ref, ok := openrpc.RefOf(m); ok {
+		if component, ok := b.component(ref); ok {
+			return component
+		}
+
+		target, err := b.res.Deref(m)
+		if err != nil {
+			return "unknown"
+		}
+
+		m, _ = target.(map[string]interface{})
+	}
+
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alts, ok := m[key].([]interface{}); ok {
+			types := make([]string, len(alts))
+			for i, alt := range alts {
+				am, _ := alt.(map[string]interface{})
+				types[i] = b.typeOf(am)
+			}
+			return strings.Join(types, " or ")
+		}
+	}
+
+	var types []string
+
+	switch t := m["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+
+	if len(types) == 0 {
+		if _, ok := m["properties"]; ok {
+			types = []string{"object"}
+		} else if _, ok := m["items"]; ok {
+			types = []string{"array"}
+		} else {
+			return "any"
+		}
+	}
+
+	for i, t := range types {
+		if t == "array" {
+			item, _ := m["items"].(map[string]interface{})
+			if item != nil {
+				types[i] = b.typeOf(item) + "[]"
+			}
+		}
+	}
+
+	return strings.Join(types, " or ")
+}
+
+// properties returns the rows of the properties of an inline object schema, prefixed with the name of their parent
+func (b *pageBuilder) properties(parent string, sch interface{}, depth int) []*Field {
+
+	m, _ := sch.(map[string]interface{})
+
+	if depth > maxDepth {
+		return nil
+	}
+
+	if _, ok := openrpc.RefOf(m); ok {
+		return nil
+	}
+
+	if item, ok := m["items"].(map[string]interface{}); ok {
+		return b.properties(parent+"[]", item, depth)
+	}
+
+	props, _ := m["properties"].(map[string]interface{})
+
+	required := map[string]bool{}
+	if list, ok := m["required"].([]interface{}); ok {
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var fields []*Field
+
+	for _, k := range keys {
+		name := k
+		if parent != "" {
+			name = parent + "." + k
+		}
+
+		f := b.field(name, props[k], depth)
+		f.Required = required[k]
+
+		fields = append(fields, f)
+		fields = append(fields, b.properties(name, props[k], depth+1)...)
+	}
+
+	return fields
+}
+
+// component returns the name of the component schema a reference points to
+func (b *pageBuilder) component(ref string) (string, bool) {
+
+	ptr, err := openrpc.NewPointer(ref)
+	if err != nil {
+		return "", false
+	}
+
+	refs := ptr.Refs()
+	if len(refs) != 3 || refs[0] != "components" || refs[1] != "schemas" {
+		return "", false
+	}
+
+	_, ok := b.schemaAnchors[refs[2]]
+
+	return refs[2], ok
+}
+
+// anchor returns a unique html id made from name
+func (b *pageBuilder) anchor(name string) string {
+
+	var sb strings.Builder
+
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-':
+			sb.WriteRune(r)
+		case r == ' ' || r == '.' || r == '/':
+			sb.WriteRune('-')
+		}
+	}
+
+	id := sb.String()
+	if id == "" {
+		id = "section"
+	}
+
+	unique := id
+	for i := 2; b.anchors[unique]; i++ {
+		unique = id + "-" + strconv.Itoa(i)
+	}
+	b.anchors[unique] = true
+
+	return unique
+}
+
+func example(m *openrpc.Method, ex *openrpc.ExamplePairing) *Example {
+
+	e := &Example{Name: ex.Name, Summary: ex.Summary, Description: ex.Description}
+
+	if len(ex.Params) > 0 {
+		e.Params = indent(docutil.ExampleParams(m, ex))
+	}
+
+	if ex.Result != nil {
+		e.Result = indent(ex.Result.Value)
+	}
+
+	return e
+}
+
+func indent(v interface{}) string {
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ""
+	}
+
+	return string(data)
+}
@@ -0,0 +1,107 @@
+package render
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	openrpc "github.com/octanolabs/g0penrpc"
+	"github.com/octanolabs/g0penrpc/internal/testutil"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// TestRender compares the documentation of the petstore document with the golden files in testdata;
+// run go test -update to regenerate them after an intended change
+func TestRender(t *testing.T) {
+
+	doc := testutil.Document(t, "petstore.json")
+
+	for _, c := range []struct {
+		golden string
+		render func(*openrpc.DocumentSpec1, Options) ([]byte, error)
+	}{
+		{"testdata/petstore.md", Markdown},
+		{"testdata/petstore.html", HTML},
+	} {
+		t.Run(c.golden, func(t *testing.T) {
+
+			out, err := c.render(doc, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if *update {
+				if err := ioutil.WriteFile(c.golden, out, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expected, err := ioutil.ReadFile(c.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(out, expected) {
+				t.Errorf("error, output does not match %s:\n%s", c.golden, out)
+			}
+		})
+	}
+}
+
+func TestPage(t *testing.T) {
+
+	page, err := NewPage(testutil.Document(t, "petstore.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var groups []string
+	for _, g := range page.Groups {
+		var methods []string
+		for _, m := range g.Methods {
+			methods = append(methods, m.Name)
+		}
+		groups = append(groups, g.Tag.Name+":"+strings.Join(methods, ","))
+	}
+
+	if strings.Join(groups, " ") != "pets:list_pets,create_pet admin:create_pet :get_pet,family_tree,ping" {
+		t.Errorf("error, unexpected groups %v", groups)
+	}
+
+	params := page.Methods[1].Params
+	if len(params) != 2 || params[0].Name != "name" || !params[0].Required || params[1].Required || params[1].Depth != 0 {
+		t.Errorf("error, unexpected params of create_pet %+v", params)
+	}
+
+	if result := page.Methods[0].Result[0]; result.Type != "Pet[]" || result.Ref != "schema-pet" {
+		t.Errorf("error, unexpected result of list_pets %+v", result)
+	}
+}
+
+func TestTemplateOverride(t *testing.T) {
+
+	doc := testutil.Document(t, "petstore.json")
+
+	out, err := Markdown(doc, Options{Template: `{{define "method"}}
+
+## {{.Name}} ({{len .Params}} params){{end}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(out), "\n## create_pet (2 params)\n") || strings.Contains(string(out), "#### Params") {
+		t.Errorf("error, method template not overridden:\n%s", out)
+	}
+
+	out, err = HTML(doc, Options{Template: `{{range .Methods}}<p>{{.Name}}</p>{{end}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != "<p>list_pets</p><p>create_pet</p><p>get_pet</p><p>family_tree</p><p>ping</p>\n" {
+		t.Errorf("error, page template not replaced:\n%s", out)
+	}
+}
@@ -0,0 +1,173 @@
+package openrpc
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	jptr "github.com/qri-io/jsonpointer"
+)
+
+// RefResolver evaluates local references ("#/components/schemas/...") against the json representation of a document
+type RefResolver struct {
+	root   interface{}
+	errors *ErrorRegistry
+}
+
+// NewRefResolver takes a json snapshot of the document; later changes to doc are not seen by the resolver
+func NewRefResolver(doc *DocumentSpec1) (*RefResolver, error) {
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var root interface{}
+
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	var snapshot struct {
+		Components struct {
+			Errors *ErrorRegistry `json:"errors"`
+		} `json:"components"`
+	}
+
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+
+	errs := snapshot.Components.Errors
+	if errs == nil {
+		errs = NewErrorRegistry()
+	}
+
+	return &RefResolver{root: root, errors: errs}, nil
+}
+
+// Root returns the decoded json document
+func (r *RefResolver) Root() interface{} {
+	return r.root
+}
+
+// Resolve returns the value a local reference points to
+func (r *RefResolver) Resolve(ref string) (interface{}, error) {
+
+	if !strings.HasPrefix(ref, "#") {
+		return nil, errors.New("cannot resolve non local reference " + ref)
+	}
+
+	p, err := jptr.Parse(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	v, _, err := evalRefs(r.root, p)
+	if err != nil {
+		return nil, errors.New("cannot resolve reference " + ref + ": " + err.Error())
+	}
+
+	return v, nil
+}
+
+// Deref follows $ref chains until it reaches a schema that is not a reference
+func (r *RefResolver) Deref(sch interface{}) (interface{}, error) {
+
+	seen := map[string]bool{}
+
+	for {
+		ref, ok := RefOf(sch)
+		if !ok {
+			return sch, nil
+		}
+
+		if seen[ref] {
+			return nil, errors.New("circular reference " + ref)
+		}
+		seen[ref] = true
+
+		v, err := r.Resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		sch = v
+	}
+}
+
+// Schema returns the decoded schema of a content descriptor, without following references
+func (r *RefResolver) Schema(cd *ContentDescriptor) (interface{}, error) {
+
+	if cd == nil {
+		return nil, errors.New("nil content descriptor")
+	}
+
+	var sch interface{} = cd.Schema
+
+	if cd.Schema == nil {
+		if cd.InlineSchema == nil {
+			return nil, errors.New("content descriptor " + cd.Name + " has no schema")
+		}
+		sch = cd.InlineSchema
+	}
+
+	data, err := json.Marshal(sch)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+
+	err = json.Unmarshal(data, &v)
+
+	return v, err
+}
+
+// Error returns the error a reference points to, or e itself if it is not a reference; see ErrorRegistry.Resolve
+func (r *RefResolver) Error(e Error) (Error, error) {
+	return r.errors.Resolve(e)
+}
+
+// RefOf reports the target of a decoded reference object
+func RefOf(sch interface{}) (string, bool) {
+
+	m, ok := sch.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+
+	ref, ok := m["$ref"].(string)
+
+	return ref, ok
+}
+
+// evalRefs evaluates the references of a json pointer inside a decoded json value, one at a time with jsonpointer.
+// On failure it returns the value evaluation stopped at, and the index of the reference that could not be evaluated
+// in it, from which PointerStore.Resolve follows $refs
+func evalRefs(v interface{}, refs []string) (interface{}, int, error) {
+
+	for i, tok := range refs {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			// jsonpointer evaluates missing keys to nil, which cannot be told apart from null values
+			if _, ok := node[tok]; !ok {
+				return v, i, errors.New("no such key " + tok)
+			}
+		case []interface{}:
+			// and it only checks the upper bound of indexes
+			if strings.HasPrefix(tok, "-") {
+				return v, i, errors.New("no such index " + tok)
+			}
+		}
+
+		next, err := jptr.Pointer{tok}.Eval(v)
+		if err != nil {
+			return v, i, err
+		}
+
+		v = next
+	}
+
+	return v, len(refs), nil
+}
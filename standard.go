@@ -0,0 +1,184 @@
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The errors predefined by the JSON-RPC 2.0 specification are returned by functions, so that every caller gets
+// its own copy
+
+// ParseError returns the error sent when the server receives invalid json
+func ParseError() Error {
+	return Error{Code: -32700, Message: "Parse error"}
+}
+
+// InvalidRequest returns the error sent when the json sent is not a valid request object
+func InvalidRequest() Error {
+	return Error{Code: -32600, Message: "Invalid Request"}
+}
+
+// MethodNotFound returns the error sent when the method does not exist or is not available
+func MethodNotFound() Error {
+	return Error{Code: -32601, Message: "Method not found"}
+}
+
+// InvalidParams returns the error sent when the params of a method are invalid
+func InvalidParams() Error {
+	return Error{Code: -32602, Message: "Invalid params"}
+}
+
+// InternalError returns the error sent on internal JSON-RPC errors
+func InternalError() Error {
+	return Error{Code: -32603, Message: "Internal error"}
+}
+
+// The range of codes JSON-RPC 2.0 reserves for implementation-defined server errors
+const (
+	ServerErrorMin = -32099
+	ServerErrorMax = -32000
+)
+
+// StandardErrors returns the errors predefined by the JSON-RPC 2.0 specification, by code
+func StandardErrors() []Error {
+	return []Error{ParseError(), InvalidRequest(), MethodNotFound(), InvalidParams(), InternalError()}
+}
+
+// ServerError returns an implementation-defined server error, failing if code is not within ServerErrorMin and ServerErrorMax
+func ServerError(code int, message string) (Error, error) {
+
+	if code < ServerErrorMin || code > ServerErrorMax {
+		return Error{}, errors.New("server error code " + strconv.Itoa(code) + " is not within -32099 and -32000")
+	}
+
+	if message == "" {
+		return Error{}, errors.New("server error " + strconv.Itoa(code) + " has no message")
+	}
+
+	return Error{Code: code, Message: message}, nil
+}
+
+// AttachErrors adds errs to the errors of every method of doc, skipping the errors a method already lists: the same
+// references, or errors with the same code once references to components/errors are resolved
+func (doc *DocumentSpec1) AttachErrors(errs ...Error) {
+
+	registry := doc.sections().Errors
+
+	for _, m := range doc.Methods {
+		if m == nil {
+			continue
+		}
+
+		for _, e := range errs {
+			if !listsError(registry, m, e) {
+				m.Errors = append(m.Errors, e)
+			}
+		}
+	}
+}
+
+func listsError(registry *ErrorRegistry, m *Method, e Error) bool {
+
+	resolved, err := registry.Resolve(e)
+
+	for _, other := range m.Errors {
+		if e.Ref != nil && other.Ref != nil && e.Ref.String() == other.Ref.String() {
+			return true
+		}
+
+		// unresolved references only match themselves
+		if otherResolved, otherErr := registry.Resolve(other); err == nil && otherErr == nil && resolved.Code == otherResolved.Code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RegisterErrors declares errs once in components/errors, named after their message in camel case (e.g. ParseError),
+// and adds references to them to the errors of every method of doc. It fails if a different error is already
+// declared under one of the names, in which case none of errs is declared
+func (doc *DocumentSpec1) RegisterErrors(errs ...Error) error {
+
+	if doc.Components == nil {
+		doc.Components = &Components{}
+	}
+
+	c := doc.Components
+
+	if c.Errors == nil {
+		c.Errors = NewErrorRegistry()
+	}
+
+	// every name is checked before any error is declared, so that a collision leaves the document as it was
+	names := make([]string, len(errs))
+	named := map[string]Error{}
+
+	for i, e := range errs {
+		name := ErrorName(e)
+		if name == "" {
+			return errors.New("error " + strconv.Itoa(e.Code) + " has no message to be named after")
+		}
+
+		existing, ok := named[name]
+		if !ok {
+			existing, ok = c.Errors.Error(name)
+		}
+
+		if ok && !sameError(existing, e) {
+			return errors.New("a different error is already declared as /components/errors/" + name)
+		}
+
+		names[i] = name
+		named[name] = e
+	}
+
+	refs := make([]Error, 0, len(errs))
+
+	for i, e := range errs {
+		ptr, err := c.Errors.Register(names[i], e)
+		if err != nil {
+			return err
+		}
+
+		refs = append(refs, Error{Ref: ptr})
+	}
+
+	doc.AttachErrors(refs...)
+
+	return nil
+}
+
+// sameError reports whether two errors have the same json encoding, as registries require of values declared twice
+func sameError(a, b Error) bool {
+
+	x, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+
+	y, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(x, y)
+}
+
+// ErrorName returns the camel case name of an error made from its message, e.g. MethodNotFound for "Method not found"
+func ErrorName(e Error) string {
+
+	var sb strings.Builder
+
+	for _, word := range strings.FieldsFunc(e.Message, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		runes := []rune(word)
+		sb.WriteRune(unicode.ToUpper(runes[0]))
+		sb.WriteString(string(runes[1:]))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,144 @@
+package openrpc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const standardDocument = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "test", "version": "1"},
+	"methods": [
+		{"name": "a", "params": [], "result": {"name": "r", "schema": {}}, "errors": [{"code": -32602, "message": "Bad params"}]},
+		{"name": "b", "params": [], "result": {"name": "r", "schema": {}}}
+	]
+}`
+
+func TestServerError(t *testing.T) {
+
+	if e, err := ServerError(-32001, "Rate limited"); err != nil || e.Code != -32001 {
+		t.Errorf("error, unexpected %v, %v", e, err)
+	}
+
+	for _, code := range []int{-32100, -31999, 1} {
+		if _, err := ServerError(code, "out of range"); err == nil {
+			t.Errorf("error, code %d should be rejected", code)
+		}
+	}
+}
+
+func TestAttachErrors(t *testing.T) {
+
+	doc, err := ParseDocument([]byte(standardDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc.AttachErrors(StandardErrors()...)
+	doc.AttachErrors(StandardErrors()...)
+
+	codes := func(m *Method) []int {
+		var c []int
+		for _, e := range m.Errors {
+			c = append(c, e.Code)
+		}
+		return c
+	}
+
+	// the -32602 error of a is kept as declared
+	if c := codes(doc.Methods[0]); len(c) != 5 || c[0] != -32602 || doc.Methods[0].Errors[0].Message != "Bad params" {
+		t.Errorf("error, unexpected errors of a %v", doc.Methods[0].Errors)
+	}
+
+	if c := codes(doc.Methods[1]); len(c) != 5 || c[0] != -32700 || c[4] != -32603 {
+		t.Errorf("error, unexpected errors of b %v", c)
+	}
+}
+
+func TestRegisterErrors(t *testing.T) {
+
+	doc, err := ParseDocument([]byte(standardDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limited, err := ServerError(-32001, "Rate limited")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errs := append(StandardErrors(), limited)
+
+	if err := doc.RegisterErrors(errs...); err != nil {
+		t.Fatal(err)
+	}
+
+	// registering again is a no-op
+	if err := doc.RegisterErrors(errs...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := doc.RegisterErrors(Error{Code: 1, Message: "Parse error"}); err == nil {
+		t.Error("error, registering a different error under a taken name should fail")
+	}
+
+	// a collision is found before any error is declared
+	if err := doc.RegisterErrors(Error{Code: -32002, Message: "Busy"}, Error{Code: 2, Message: "Busy"}); err == nil {
+		t.Error("error, registering two different errors under the same name should fail")
+	}
+
+	if _, ok := doc.Components.Errors.Error("Busy"); ok {
+		t.Error("error, expected no error to be declared after a collision")
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := string(data)
+
+	for _, expected := range []string{
+		// a keeps its inline -32602 error instead of a reference to InvalidParams
+		`"errors":[{"code":-32602,"message":"Bad params"},{"$ref":"#/components/errors/ParseError"},{"$ref":"#/components/errors/InvalidRequest"},` +
+			`{"$ref":"#/components/errors/MethodNotFound"},{"$ref":"#/components/errors/InternalError"},{"$ref":"#/components/errors/RateLimited"}]`,
+		`"components":{"errors":{"ParseError":{"code":-32700,"message":"Parse error"},"InvalidRequest":{"code":-32600,"message":"Invalid Request"},` +
+			`"MethodNotFound":{"code":-32601,"message":"Method not found"},"InvalidParams":{"code":-32602,"message":"Invalid params"},` +
+			`"InternalError":{"code":-32603,"message":"Internal error"},"RateLimited":{"code":-32001,"message":"Rate limited"}}}`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("error, %s does not contain %s", out, expected)
+		}
+	}
+
+	parsed, err := ParseDocument(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := parsed.Validate(); err != nil {
+		t.Errorf("error, document with error references should be valid: %v", err)
+	}
+
+	res, err := NewRefResolver(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := res.Error(parsed.Methods[1].Errors[5])
+	if err != nil || e != limited {
+		t.Errorf("error, expected %v, got %v, %v", limited, e, err)
+	}
+
+	t.Run("unresolved", func(t *testing.T) {
+
+		missing, _ := NewPointer("/components/errors/Missing")
+		parsed.Methods[0].Errors = append(parsed.Methods[0].Errors, Error{Ref: missing})
+
+		err := parsed.Validate()
+		if err == nil || err.Error() != "methods/0/errors/6: unresolved reference #/components/errors/Missing" {
+			t.Errorf("error, got %v instead of an unresolved reference", err)
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package openrpc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TagRegistry holds the tags declared in components/tags, by name; it is safe for concurrent use
+type TagRegistry struct {
+	componentRegistry
+}
+
+// NewTagRegistry returns an empty registry of the components/tags section
+func NewTagRegistry() *TagRegistry {
+	return &TagRegistry{componentRegistry{store: newComponentStore(tagsSection)}}
+}
+
+// Register declares t under its name and returns the pointer by which methods reference it;
+// it fails if a different tag is already declared under the name
+func (r *TagRegistry) Register(t Tag) (Pointer, error) {
+
+	return r.init(tagsSection).declare(t.Name, t)
+}
+
+// Tag returns the tag declared as name
+func (r *TagRegistry) Tag(name string) (Tag, bool) {
+
+	v, ok := r.init(tagsSection).get(name)
+	if !ok {
+		return Tag{}, false
+	}
+
+	return v.(Tag), true
+}
+
+// Ref returns a reference to the tag declared as name, to be listed in the tags of methods
+func (r *TagRegistry) Ref(name string) (Tag, bool) {
+
+	ptr, ok := r.ref(name)
+
+	return Tag{Ref: ptr}, ok
+}
+
+// Tags returns the declared tags, in the order they were declared
+func (r *TagRegistry) Tags() []Tag {
+
+	var tags []Tag
+
+	for _, name := range r.init(tagsSection).list() {
+		if t, ok := r.Tag(name); ok {
+			tags = append(tags, t)
+		}
+	}
+
+	return tags
+}
+
+// Resolve returns the tag a reference points to, or t itself if it is not a reference
+func (r *TagRegistry) Resolve(t Tag) (Tag, error) {
+
+	if t.Ref == nil {
+		return t, nil
+	}
+
+	v, err := r.init(tagsSection).resolve(t.Ref)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	return v.(Tag), nil
+}
+
+func (r *TagRegistry) UnmarshalJSON(data []byte) error {
+	return r.unmarshalJSON(data, tagsSection)
+}
+
+// TagGroup is a tag and the methods tagged with it
+type TagGroup struct {
+	Tag     Tag
+	Methods []*Method
+}
+
+// Tags returns the tags of a method, with references to components/tags resolved
+func (doc *DocumentSpec1) Tags(m *Method) ([]Tag, error) {
+
+	tags := make([]Tag, 0, len(m.Tags))
+	reg := doc.sections().Tags
+
+	for i, t := range m.Tags {
+		t, err := reg.Resolve(t)
+		if err != nil {
+			return nil, fmt.Errorf("tags/%d: %v", i, err)
+		}
+
+		tags = append(tags, t)
+	}
+
+	return tags, nil
+}
+
+// MethodsByTag groups the methods of doc by tag, in the order tags first appear in methods; methods with several tags
+// are in several groups, untagged methods are in a last group with an empty tag. Tags listed inline take the summary
+// and description of the first occurrence that has any
+func (doc *DocumentSpec1) MethodsByTag() ([]*TagGroup, error) {
+
+	var (
+		groups   []*TagGroup
+		untagged *TagGroup
+		byName   = map[string]*TagGroup{}
+	)
+
+	for _, m := range doc.Methods {
+		if m == nil {
+			continue
+		}
+
+		tags, err := doc.Tags(m)
+		if err != nil {
+			return nil, errors.New("method " + m.Name + ": " + err.Error())
+		}
+
+		if len(tags) == 0 {
+			if untagged == nil {
+				untagged = &TagGroup{}
+			}
+			untagged.Methods = append(untagged.Methods, m)
+		}
+
+		for _, t := range tags {
+			g, ok := byName[t.Name]
+			if !ok {
+				g = &TagGroup{Tag: t}
+				byName[t.Name] = g
+				groups = append(groups, g)
+			}
+
+			if g.Tag.Summary == "" && g.Tag.Description == "" {
+				g.Tag = t
+			}
+
+			g.Methods = append(g.Methods, m)
+		}
+	}
+
+	if untagged != nil {
+		groups = append(groups, untagged)
+	}
+
+	return groups, nil
+}
+
+// TagMethod adds references to the tags declared as names to the tags of m, skipping those m already lists;
+// it fails if a tag is not declared in components/tags
+func (doc *DocumentSpec1) TagMethod(m *Method, names ...string) error {
+
+	for _, name := range names {
+		if doc.Components == nil || doc.Components.Tags == nil {
+			return errors.New("tag " + name + " is not declared in components/tags")
+		}
+
+		ref, ok := doc.Components.Tags.Ref(name)
+		if !ok {
+			return errors.New("tag " + name + " is not declared in components/tags")
+		}
+
+		listed := false
+		for _, t := range m.Tags {
+			if (t.Ref != nil && t.Ref.String() == ref.Ref.String()) || (t.Ref == nil && t.Name == name) {
+				listed = true
+				break
+			}
+		}
+
+		if !listed {
+			m.Tags = append(m.Tags, ref)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package openrpc
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const tagsDocument = `{
+	"openrpc": "1.2.6",
+	"info": {"title": "test", "version": "1"},
+	"methods": [
+		{"name": "a", "tags": [{"$ref": "#/components/tags/pets"}], "params": [], "result": {"name": "r", "schema": {}}},
+		{"name": "b", "params": [], "result": {"name": "r", "schema": {}}},
+		{"name": "c", "tags": [{"name": "admin"}, {"$ref": "#/components/tags/pets"}], "params": [], "result": {"name": "r", "schema": {}}}
+	],
+	"components": {
+		"tags": {
+			"pets": {"name": "pets", "description": "Everything about pets"},
+			"admin": {"name": "admin", "summary": "Administration"}
+		}
+	}
+}`
+
+func TestTagRegistry(t *testing.T) {
+
+	doc, err := ParseDocument([]byte(tagsDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags := doc.Components.Tags
+
+	if names := strings.Join(tags.Names(), ","); names != "pets,admin" {
+		t.Errorf("error, expected tags in declaration order, got %s", names)
+	}
+
+	if _, err := tags.Register(Tag{Name: "pets", Description: "Everything about pets"}); err != nil {
+		t.Errorf("error, registering an identical tag should succeed: %v", err)
+	}
+
+	if _, err := tags.Register(Tag{Name: "pets", Description: "Other"}); err == nil {
+		t.Errorf("error, registering a different tag under a taken name should fail")
+	}
+
+	resolved, err := tags.Resolve(doc.Methods[0].Tags[0])
+	if err != nil || resolved.Description != "Everything about pets" {
+		t.Errorf("error, unexpected resolved tag %v, %v", resolved, err)
+	}
+
+	if _, err := tags.Resolve(Tag{Ref: newPointerFromRefs([]string{"components", "tags", "missing"})}); err == nil {
+		t.Errorf("error, resolving an undeclared tag should fail")
+	}
+
+	if _, err := tags.Resolve(Tag{Ref: newPointerFromRefs([]string{"components", "schemas", "pets"})}); err == nil {
+		t.Errorf("error, resolving a reference outside components/tags should fail")
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`"tags":[{"$ref":"#/components/tags/pets"}]`,
+		`"tags":{"pets":{"name":"pets","description":"Everything about pets"},"admin":{"name":"admin","summary":"Administration"}}`,
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("error, expected %s in %s", expected, data)
+		}
+	}
+
+	if !tags.Remove("admin") || tags.Remove("admin") {
+		t.Errorf("error, expected admin to be removed once")
+	}
+}
+
+func TestMethodsByTag(t *testing.T) {
+
+	doc, err := ParseDocument([]byte(tagsDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups, err := doc.MethodsByTag()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, g := range groups {
+		names := make([]string, len(g.Methods))
+		for i, m := range g.Methods {
+			names[i] = m.Name
+		}
+		got = append(got, g.Tag.Name+":"+strings.Join(names, ","))
+	}
+
+	if strings.Join(got, " ") != "pets:a,c admin:c :b" {
+		t.Errorf("error, unexpected groups %v", got)
+	}
+
+	if groups[0].Tag.Description != "Everything about pets" {
+		t.Errorf("error, expected the pets group to take the declared tag, got %v", groups[0].Tag)
+	}
+
+	doc.Components.Tags.Remove("pets")
+
+	if _, err := doc.MethodsByTag(); err == nil || !strings.Contains(err.Error(), "method a") {
+		t.Errorf("error, expected a reference to an undeclared tag to fail, got %v", err)
+	}
+}
+
+func TestTagMethod(t *testing.T) {
+
+	doc, err := ParseDocument([]byte(tagsDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := doc.Methods[1]
+
+	if err := doc.TagMethod(b, "pets", "admin", "pets"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(b.Tags) != 2 || b.Tags[0].Ref.String() != "/components/tags/pets" {
+		t.Errorf("error, unexpected tags %v", b.Tags)
+	}
+
+	// c lists admin inline already
+	c := doc.Methods[2]
+
+	if err := doc.TagMethod(c, "admin"); err != nil || len(c.Tags) != 2 {
+		t.Errorf("error, expected admin not to be added twice, got %v, %v", c.Tags, err)
+	}
+
+	if err := doc.TagMethod(b, "missing"); err == nil {
+		t.Errorf("error, tagging with an undeclared tag should fail")
+	}
+
+	if err := doc.Validate(); err != nil {
+		t.Errorf("error, unexpected validation errors %v", err)
+	}
+}
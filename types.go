@@ -1,13 +1,23 @@
 package openrpc
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
 )
 
-// PointerTree is used to represent the hierarchy of properties of a json object
+// PointerTree is used to represent the hierarchy of properties of a json object; it is safe for concurrent use
 type PointerTree struct {
-	ptr   Pointer
+	ptr Pointer
+	// mu guards nodes and keys, every node of a tree has its own lock
+	mu    sync.RWMutex
 	nodes map[string]*PointerTree
+	// keys holds the keys of nodes in insertion order
+	keys []string
 }
 
 func NewPointerTree(ptr Pointer) *PointerTree {
@@ -17,81 +27,359 @@ func NewPointerTree(ptr Pointer) *PointerTree {
 	}
 }
 
+// equals reports whether two trees have the same pointers and the same hierarchy of children
 func (pt *PointerTree) equals(opt *PointerTree) bool {
 
-	pt1 := pt.ptr.Refs()
-	pt2 := opt.ptr.Refs()
+	if pt == nil || opt == nil {
+		return pt == opt
+	}
 
-	if len(pt1) == 0 && len(pt2) == 0 {
-		return true
+	if !refsEqual(pt.ptr, opt.ptr) {
+		return false
 	}
 
-	if len(pt1) != len(pt2) {
+	_, nodes := pt.children(InsertionOrder)
+	_, otherNodes := opt.children(InsertionOrder)
+
+	if len(nodes) != len(otherNodes) {
 		return false
 	}
 
-	for i := 0; i < len(pt1); i++ {
-		if pt1[i] != pt2[i] {
+	for key, node := range nodes {
+		other, ok := otherNodes[key]
+		if !ok {
+			return false
+		}
+
+		if !node.equals(other) {
 			return false
 		}
 	}
 
-	for key := range pt.nodes {
-		if _, ok := opt.nodes[key]; !ok {
+	return true
+}
+
+func refsEqual(p1, p2 Pointer) bool {
+
+	var pt1, pt2 []string
+
+	if p1 != nil {
+		pt1 = p1.Refs()
+	}
+
+	if p2 != nil {
+		pt2 = p2.Refs()
+	}
+
+	if len(pt1) != len(pt2) {
+		return false
+	}
+
+	for i := 0; i < len(pt1); i++ {
+		if pt1[i] != pt2[i] {
 			return false
 		}
 	}
 
-	//TODO: maybe compare pointers to other trees too?
 	return true
 }
 
-//ResolvePointers recursively marshals a tree;
-//if a tree has no children it is treated as a pointer and used to fetch a Schema from the registry
-func (pt *PointerTree) ResolvePointers(reg *PointerStore) (json.RawMessage, error) {
+// DiffKind tells how a path differs between two trees
+type DiffKind int
 
-	result := make(map[string]json.RawMessage)
+const (
+	// PathAdded is a path found only in the second tree
+	PathAdded DiffKind = iota
+	// PathRemoved is a path found only in the first tree
+	PathRemoved
+	// PathChanged is a path found in both trees, pointing to different schemas
+	PathChanged
+)
 
-	if len(pt.nodes) == 0 {
+func (k DiffKind) String() string {
+	switch k {
+	case PathAdded:
+		return "added"
+	case PathRemoved:
+		return "removed"
+	case PathChanged:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
 
-		var (
-			b   = make([]byte, 0)
-			err error
-		)
+// PathDiff is a path, relative to the roots of the compared trees, that differs between them;
+// when a whole subtree is added or removed only its root is reported
+type PathDiff struct {
+	Kind DiffKind
+	Path Pointer
+}
+
+func (d PathDiff) String() string {
+	return d.Kind.String() + " " + d.Path.String()
+}
+
+// Diff recursively compares two trees; leaves are compared by the schemas they point to, in reg and otherReg respectively
+func (pt *PointerTree) Diff(reg *PointerStore, other *PointerTree, otherReg *PointerStore) ([]PathDiff, error) {
+
+	diffs := []PathDiff{}
+
+	err := pt.diff(nil, reg, other, otherReg, &diffs)
+
+	return diffs, err
+}
 
-		//fmt.Println(pt.ptr.String())
-		if sch, ok := reg.Get(pt.ptr); ok {
-			b, err = sch.MarshalJSON()
+func (pt *PointerTree) diff(path []string, reg *PointerStore, other *PointerTree, otherReg *PointerStore, diffs *[]PathDiff) error {
+
+	changed := PathDiff{Kind: PathChanged, Path: newPointerFromRefs(path)}
+
+	_, nodes := pt.children(InsertionOrder)
+	_, otherNodes := other.children(InsertionOrder)
+
+	if len(nodes) == 0 || len(otherNodes) == 0 {
+		if len(nodes) != len(otherNodes) {
+			*diffs = append(*diffs, changed)
+			return nil
+		}
+
+		sch, ok := reg.Get(pt.ptr)
+		otherSch, otherOk := otherReg.Get(other.ptr)
+
+		if ok != otherOk {
+			*diffs = append(*diffs, changed)
+			return nil
+		}
+
+		if ok {
+			equal, err := schemasEqual(sch, otherSch)
 			if err != nil {
-				return nil, err
+				return err
+			}
+
+			if !equal {
+				*diffs = append(*diffs, changed)
 			}
 		}
 
-		return b, nil
+		return nil
+	}
+
+	keys := make([]string, 0, len(nodes)+len(otherNodes))
+	for key := range nodes {
+		keys = append(keys, key)
+	}
+	for key := range otherNodes {
+		if _, ok := nodes[key]; !ok {
+			keys = append(keys, key)
+		}
 	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := append(append([]string{}, path...), key)
+
+		node, ok := nodes[key]
+		otherNode, otherOk := otherNodes[key]
+
+		switch {
+		case !otherOk:
+			*diffs = append(*diffs, PathDiff{Kind: PathRemoved, Path: newPointerFromRefs(childPath)})
+		case !ok:
+			*diffs = append(*diffs, PathDiff{Kind: PathAdded, Path: newPointerFromRefs(childPath)})
+		default:
+			if err := node.diff(childPath, reg, otherNode, otherReg, diffs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// schemasEqual compares the json values of two schemas, regardless of key order and formatting
+func schemasEqual(s1, s2 Schema) (bool, error) {
 
-	for prop, tree := range pt.nodes {
+	var v1, v2 interface{}
 
-		s, err := tree.ResolvePointers(reg)
+	for _, item := range []struct {
+		sch Schema
+		v   *interface{}
+	}{{s1, &v1}, {s2, &v2}} {
+		data, err := item.sch.MarshalJSON()
 		if err != nil {
-			return nil, err
+			return false, err
+		}
+
+		if err := json.Unmarshal(data, item.v); err != nil {
+			return false, err
+		}
+	}
+
+	return reflect.DeepEqual(v1, v2), nil
+}
+
+// Ordering selects the order in which object keys are marshaled
+type Ordering int
+
+const (
+	// InsertionOrder writes the children of a tree in the order they were inserted,
+	// and schemas as they were decoded (reflected structs list their fields in declaration order)
+	InsertionOrder Ordering = iota
+	// Alphabetical sorts the children of a tree, and the keys of every object in a schema
+	Alphabetical
+)
+
+//ResolvePointers recursively marshals a tree, writing children in insertion order;
+//if a tree has no children it is treated as a pointer and used to fetch a Schema from the registry
+func (pt *PointerTree) ResolvePointers(reg *PointerStore) (json.RawMessage, error) {
+	return pt.ResolvePointersOrdered(reg, InsertionOrder)
+}
+
+//ResolvePointersOrdered is like ResolvePointers, writing object keys in the given order
+func (pt *PointerTree) ResolvePointersOrdered(reg *PointerStore, order Ordering) (json.RawMessage, error) {
+
+	var buf bytes.Buffer
+
+	if err := pt.Encode(&buf, reg, order); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Encode streams the json of a tree to w: children are written as object keys in the given order,
+// leaves as the compacted Schema they point to in reg, or as an empty object if reg has none
+func (pt *PointerTree) Encode(w io.Writer, reg *PointerStore, order Ordering) error {
+
+	jw := &jsonWriter{w: w}
+
+	pt.encode(jw, reg, order)
+
+	return jw.err
+}
+
+func (pt *PointerTree) encode(w *jsonWriter, reg *PointerStore, order Ordering) {
+
+	keys, nodes := pt.children(order)
+
+	if len(nodes) == 0 {
+
+		sch, ok := reg.Get(pt.ptr)
+		if !ok {
+			w.write([]byte("{}"))
+			return
+		}
+
+		b, err := sch.MarshalJSON()
+		if err == nil && order == Alphabetical {
+			b, err = sortKeys(b)
+		}
+
+		if err != nil {
+			w.fail(err)
+			return
 		}
-		result[prop] = s
 
+		w.writeCompact(b)
+
+		return
+	}
+
+	w.write([]byte{'{'})
+
+	for i, prop := range keys {
+
+		if i > 0 {
+			w.write([]byte{','})
+		}
+
+		name, _ := json.Marshal(prop)
+		w.write(name)
+		w.write([]byte{':'})
+
+		nodes[prop].encode(w, reg, order)
+	}
+
+	w.write([]byte{'}'})
+}
+
+// jsonWriter keeps the first error occurred writing to w, and ignores any later write
+type jsonWriter struct {
+	w   io.Writer
+	err error
+	buf bytes.Buffer
+}
+
+func (jw *jsonWriter) fail(err error) {
+	if jw.err == nil {
+		jw.err = err
+	}
+}
+
+func (jw *jsonWriter) write(b []byte) {
+	if jw.err == nil {
+		_, jw.err = jw.w.Write(b)
+	}
+}
+
+func (jw *jsonWriter) writeCompact(b []byte) {
+
+	jw.buf.Reset()
+
+	if err := json.Compact(&jw.buf, b); err != nil {
+		jw.fail(err)
+		return
+	}
+
+	jw.write(jw.buf.Bytes())
+}
+
+// children returns copies of the ordered keys and of the children of a tree, so that they can be
+// traversed without holding the lock; trees that were not built through Insert have their keys sorted
+func (pt *PointerTree) children(order Ordering) ([]string, map[string]*PointerTree) {
+
+	pt.mu.RLock()
+	defer pt.mu.RUnlock()
+
+	nodes := make(map[string]*PointerTree, len(pt.nodes))
+	for key, node := range pt.nodes {
+		nodes[key] = node
+	}
+
+	if order == InsertionOrder && len(pt.keys) == len(pt.nodes) {
+		return append([]string{}, pt.keys...), nodes
 	}
 
-	b, err := json.Marshal(result)
-	if err != nil {
+	keys := make([]string, 0, len(pt.nodes))
+	for key := range pt.nodes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys, nodes
+}
+
+// sortKeys re-encodes a json value with the keys of every object sorted
+func sortKeys(data []byte) ([]byte, error) {
+
+	var v interface{}
+
+	if err := json.Unmarshal(data, &v); err != nil {
 		return nil, err
 	}
 
-	return b, nil
+	return json.Marshal(v)
 }
 
 func (pt *PointerTree) MarshalJSON() ([]byte, error) {
-	return json.Marshal(pt.nodes)
+
+	_, nodes := pt.children(InsertionOrder)
+
+	return json.Marshal(nodes)
 }
 
+// Insert adds the nodes of pointer p that are missing from the tree; existing nodes keep their children
 func (pt *PointerTree) Insert(p Pointer) *PointerTree {
 
 	elems := p.Refs()
@@ -101,7 +389,7 @@ func (pt *PointerTree) Insert(p Pointer) *PointerTree {
 	}
 
 	if len(elems) == 1 {
-		pt.nodes[elems[0]] = NewPointerTree(p)
+		pt.setNode(elems[0], NewPointerTree(p))
 		return pt
 	}
 
@@ -113,47 +401,247 @@ func (pt *PointerTree) Insert(p Pointer) *PointerTree {
 		pt.Insert(newPointerFromRefs(elems[:len(elems)-1]))
 		pt.Insert(newPointerFromRefs(elems))
 	} else {
-		t.nodes[elems[len(elems)-1]] = NewPointerTree(newPointerFromRefs(elems))
+		t.setNode(elems[len(elems)-1], NewPointerTree(newPointerFromRefs(elems)))
 	}
 
 	return pt
 }
 
+// setNode sets the child tree under key unless a child already exists, keeping track of the insertion order of keys
+func (pt *PointerTree) setNode(key string, tree *PointerTree) {
+
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	if _, ok := pt.nodes[key]; !ok {
+		pt.keys = append(pt.keys, key)
+		pt.nodes[key] = tree
+	}
+}
+
+// Remove deletes the node of pointer p and all its descendants from the tree,
+// and returns the removed subtree, or nil if p is not in the tree
+func (pt *PointerTree) Remove(p Pointer) *PointerTree {
+
+	elems := p.Refs()
+
+	if len(elems) == 0 {
+		return nil
+	}
+
+	parent := pt.Find(newPointerFromRefs(elems[:len(elems)-1]))
+	if parent == nil {
+		return nil
+	}
+
+	key := elems[len(elems)-1]
+
+	parent.mu.Lock()
+	defer parent.mu.Unlock()
+
+	removed, ok := parent.nodes[key]
+	if !ok {
+		return nil
+	}
+
+	delete(parent.nodes, key)
+
+	for i, k := range parent.keys {
+		if k == key {
+			parent.keys = append(parent.keys[:i:i], parent.keys[i+1:]...)
+			break
+		}
+	}
+
+	return removed
+}
+
+// Pointers returns the pointers of all the descendants of the tree, parents before their children,
+// in the order they were inserted
+func (pt *PointerTree) Pointers() []Pointer {
+
+	var ptrs []Pointer
+
+	pt.Walk(nil, func(ptr Pointer, _ Schema, _ int) error {
+		ptrs = append(ptrs, ptr)
+		return nil
+	})
+
+	return ptrs
+}
+
+// Find returns the subtree of pointer match, or nil if match is not in the tree
 func (pt *PointerTree) Find(match Pointer) *PointerTree {
 
 	elms := match.Refs()
 
-	for _, item := range elms {
-		if subTree, ok := pt.nodes[item]; ok {
-			if len(elms) > 1 {
-				return subTree.Find(newPointerFromRefs(elms[1:]))
-			} else {
-				return subTree
-			}
-		}
+	if len(elms) == 0 {
+		return pt
 	}
-	return nil
+
+	pt.mu.RLock()
+	subTree, ok := pt.nodes[elms[0]]
+	pt.mu.RUnlock()
+
+	if !ok {
+		return nil
+	}
+
+	return subTree.Find(newPointerFromRefs(elms[1:]))
 }
 
-// PointerStore is a simple collection of json pointers
+// PointerStore is a simple collection of json pointers; it is safe for concurrent use
 type PointerStore struct {
-	m map[string]Schema
+	mu sync.RWMutex
+	m  map[string]storeEntry
 }
 
-func (r *PointerStore) Set(pointer Pointer, item Schema) {
-	p := pointer.String()
-	if pointer == nil {
-		p = "/"
+type storeEntry struct {
+	ptr Pointer
+	sch Schema
+}
+
+func storeKey(p Pointer) string {
+	if p == nil {
+		return "/"
 	}
-	if _, ok := r.m[p]; !ok {
-		r.m[p] = item
+	return p.String()
+}
+
+// Set stores item under pointer, replacing the schema already stored there
+func (r *PointerStore) Set(pointer Pointer, item Schema) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.m[storeKey(pointer)] = storeEntry{ptr: pointer, sch: item}
+}
+
+// Add stores item under pointer unless a schema is already stored there, and reports whether it did
+func (r *PointerStore) Add(pointer Pointer, item Schema) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[storeKey(pointer)]; ok {
+		return false
 	}
+
+	r.m[storeKey(pointer)] = storeEntry{ptr: pointer, sch: item}
+	return true
 }
+
 func (r *PointerStore) Get(p Pointer) (s Schema, ok bool) {
-	s, ok = r.m[p.String()]
-	return
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.m[storeKey(p)]
+	return e.sch, ok
+}
+
+// Delete removes the schema stored under p, and reports whether there was one
+func (r *PointerStore) Delete(p Pointer) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.m[storeKey(p)]; !ok {
+		return false
+	}
+
+	delete(r.m, storeKey(p))
+	return true
+}
+
+// Len returns the number of schemas in the store
+func (r *PointerStore) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return len(r.m)
+}
+
+// Keys returns the pointers of the store, sorted by their string representation
+func (r *PointerStore) Keys() []Pointer {
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]string, 0, len(r.m))
+	for k := range r.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	ptrs := make([]Pointer, len(keys))
+	for i, k := range keys {
+		ptrs[i] = r.m[k].ptr
+	}
+
+	return ptrs
+}
+
+// Range calls fn for every schema of the store in the order of Keys, stopping if fn returns false.
+// The store is not locked while fn runs, so fn may modify it
+func (r *PointerStore) Range(fn func(ptr Pointer, sch Schema) bool) {
+	for _, p := range r.Keys() {
+		if sch, ok := r.Get(p); ok && !fn(p, sch) {
+			return
+		}
+	}
+}
+
+// Resolve returns the decoded json value ptr points to: the schema registered under the longest prefix of ptr
+// is decoded and the rest of ptr is evaluated inside it with evalRefs, following the $refs it meets along the way
+func (r *PointerStore) Resolve(ptr Pointer) (interface{}, error) {
+	return r.resolve(ptr, map[string]bool{})
+}
+
+func (r *PointerStore) resolve(ptr Pointer, seen map[string]bool) (interface{}, error) {
+
+	if seen[storeKey(ptr)] {
+		return nil, errors.New("circular reference " + ptr.String())
+	}
+	seen[storeKey(ptr)] = true
+
+	refs := ptr.Refs()
+
+	for i := len(refs); i >= 0; i-- {
+		sch, ok := r.Get(newPointerFromRefs(refs[:i]))
+		if !ok {
+			continue
+		}
+
+		data, err := sch.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+
+		var v interface{}
+
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+
+		v, j, err := evalRefs(v, refs[i:])
+		if err == nil {
+			return v, nil
+		}
+
+		// evaluation stopped at a reference object, continue from the schema it points to
+		ref, isRef := RefOf(v)
+		if !isRef {
+			return nil, errors.New("cannot resolve " + ptr.String() + " in schema " + newPointerFromRefs(refs[:i]).String() + ": " + err.Error())
+		}
+
+		target, err := NewPointer(ref)
+		if err != nil {
+			return nil, err
+		}
+
+		return r.resolve(appendRefs(target, refs[i+j:]...), seen)
+	}
+
+	return nil, errors.New("no schema registered under " + ptr.String() + " or any of its parents")
 }
 
 func NewPointerRegistry() *PointerStore {
-	return &PointerStore{m: map[string]Schema{}}
+	return &PointerStore{m: map[string]storeEntry{}}
 }
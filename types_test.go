@@ -1,12 +1,17 @@
 package openrpc
 
 import (
+	"encoding/json"
 	jptr "github.com/qri-io/jsonpointer"
 	"testing"
 )
 
 type treeTestStruct struct{ t1, t2 *PointerTree }
 
+func leaf(refs ...string) *PointerTree {
+	return NewPointerTree(newPointerFromRefs(refs))
+}
+
 var treeTestData = struct {
 	first, second, third, fourth treeTestStruct
 }{
@@ -14,66 +19,66 @@ var treeTestData = struct {
 		t1: &PointerTree{
 			ptr: newPointerFromRefs([]string{}),
 			nodes: map[string]*PointerTree{
-				"field1": nil,
-				"field2": nil,
-				"field3": nil,
+				"field1": leaf("field1"),
+				"field2": leaf("field2"),
+				"field3": leaf("field3"),
 			},
 		},
 		t2: &PointerTree{
 			ptr: newPointerFromRefs([]string{}),
 			nodes: map[string]*PointerTree{
-				"field1": nil,
-				"field2": nil,
-				"field3": nil,
+				"field1": leaf("field1"),
+				"field2": leaf("field2"),
+				"field3": leaf("field3"),
 			},
 		}},
 	treeTestStruct{
 		t1: &PointerTree{
 			ptr: newPointerFromRefs([]string{"root", "child"}),
 			nodes: map[string]*PointerTree{
-				"field1": nil,
-				"field2": nil,
-				"field3": nil,
+				"field1": leaf("field1"),
+				"field2": leaf("field2"),
+				"field3": leaf("field3"),
 			},
 		},
 		t2: &PointerTree{
 			ptr: newPointerFromRefs([]string{"root", "child"}),
 			nodes: map[string]*PointerTree{
-				"field1": nil,
-				"field2": nil,
-				"field3": nil,
+				"field1": leaf("field1"),
+				"field2": leaf("field2"),
+				"field3": leaf("field3"),
 			},
 		}},
 	treeTestStruct{
 		t1: &PointerTree{
 			ptr: newPointerFromRefs([]string{"root"}),
 			nodes: map[string]*PointerTree{
-				"field1": nil,
-				"field2": nil,
-				"field3": nil,
+				"field1": leaf("field1"),
+				"field2": leaf("field2"),
+				"field3": leaf("field3"),
 			},
 		},
 		t2: &PointerTree{
 			ptr: newPointerFromRefs([]string{"root", "child"}),
 			nodes: map[string]*PointerTree{
-				"field1": nil,
-				"field2": nil,
-				"field3": nil,
+				"field1": leaf("field1"),
+				"field2": leaf("field2"),
+				"field3": leaf("field3"),
 			},
 		}},
 	treeTestStruct{
 		t1: &PointerTree{
 			ptr: newPointerFromRefs([]string{"root", "child"}),
 			nodes: map[string]*PointerTree{
-				"field1": nil,
-				"field3": nil,
+				"field1": leaf("field1"),
+				"field3": leaf("field3"),
 			},
 		},
 		t2: &PointerTree{
 			ptr: newPointerFromRefs([]string{"root", "child"}),
 			nodes: map[string]*PointerTree{
-				"field1": nil,
-				"field2": nil,
+				"field1": leaf("field1"),
+				"field2": leaf("field2"),
 			},
 		}},
 }
@@ -198,3 +203,251 @@ func TestInsert(t *testing.T) {
 	})
 
 }
+
+func TestTreeDiff(t *testing.T) {
+
+	schema := func(data string) Schema {
+		sch := NewSchema()
+		if err := sch.UnmarshalJSON([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+		return sch
+	}
+
+	build := func(schemas map[string]string) (*PointerTree, *PointerStore) {
+		tree, store := NewPointerTree(newPointerFromRefs(nil)), NewPointerRegistry()
+		for path, data := range schemas {
+			p, err := NewPointer(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tree.Insert(p)
+			store.Set(p, schema(data))
+		}
+		return tree, store
+	}
+
+	t1, s1 := build(map[string]string{
+		"/root/a":        `{"type": "string"}`,
+		"/root/b":        `{"type": "object", "properties": {"x": {"type": "integer"}}}`,
+		"/root/c/nested": `{"type": "boolean"}`,
+		"/root/d":        `{}`,
+	})
+
+	t2, s2 := build(map[string]string{
+		"/root/a":        `{ "type" : "string" }`,
+		"/root/b":        `{"type": "object", "properties": {"x": {"type": "number"}}}`,
+		"/root/c/nested": `{"type": "boolean"}`,
+		"/root/e":        `{}`,
+	})
+
+	diffs, err := t1.Diff(s1, t2, s2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"changed /root/b", "removed /root/d", "added /root/e"}
+
+	if len(diffs) != len(want) {
+		t.Fatalf("error, got %v instead of %v", diffs, want)
+	}
+
+	for i, d := range diffs {
+		if d.String() != want[i] {
+			t.Errorf("error, got %v instead of %v", d, want[i])
+		}
+	}
+
+	t.Run("equalTrees", func(t *testing.T) {
+
+		diffs, err := t1.Diff(s1, t1, s1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(diffs) != 0 {
+			t.Errorf("error, got %v comparing a tree with itself", diffs)
+		}
+	})
+
+	t.Run("notEqualsDiffGrandchildren", func(t *testing.T) {
+
+		t3, _ := build(map[string]string{"/root/c/other": `{}`})
+		t4, _ := build(map[string]string{"/root/c/nested": `{}`})
+
+		if t3.equals(t4) {
+			t.Errorf("error, should not be equal: \n %v \n %v", t3, t4)
+		}
+	})
+}
+
+func TestPointerEscaping(t *testing.T) {
+
+	refs := []string{"components", "schemas", "Object[a/b~c]", "d e%"}
+
+	ptr := newPointerFromRefs(refs)
+
+	if ptr.String() != "/components/schemas/Object[a~1b~0c]/d e%" {
+		t.Fatalf("unexpected pointer string %s", ptr.String())
+	}
+
+	data, err := ptr.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != `{"$ref":"#/components/schemas/Object%5Ba~1b~0c%5D/d%20e%25"}` {
+		t.Fatalf("unexpected reference %s", data)
+	}
+
+	for _, path := range []string{ptr.String(), "#/components/schemas/Object%5Ba~1b~0c%5D/d%20e%25"} {
+		parsed, err := NewPointer(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !refsEqual(parsed, ptr) {
+			t.Fatalf("%s parsed as %q, expected %q", path, parsed.Refs(), refs)
+		}
+
+		tree := NewPointerTree(nil)
+		tree.Insert(parsed)
+
+		found := tree.Find(ptr)
+		if found == nil || !refsEqual(found.ptr, ptr) {
+			t.Fatalf("%s not found after insertion", path)
+		}
+	}
+
+	if _, err := NewPointer("components/schemas"); err == nil {
+		t.Fatal("expected an error for a pointer not starting with /")
+	}
+}
+
+func TestRemove(t *testing.T) {
+
+	tree := NewPointerTree(newPointerFromRefs(nil)).
+		Insert(newPointerFromRefs([]string{"root", "a", "x"})).
+		Insert(newPointerFromRefs([]string{"root", "b"})).
+		Insert(newPointerFromRefs([]string{"root", "c"}))
+
+	if removed := tree.Remove(newPointerFromRefs([]string{"root", "missing"})); removed != nil {
+		t.Errorf("error, removed a missing node: %v", removed)
+	}
+
+	removed := tree.Remove(newPointerFromRefs([]string{"root", "a"}))
+	if removed == nil || len(removed.Pointers()) != 1 {
+		t.Fatalf("error, expected the subtree of /root/a, got %v", removed)
+	}
+
+	if tree.Find(newPointerFromRefs([]string{"root", "a", "x"})) != nil {
+		t.Error("error, descendant of a removed node still found")
+	}
+
+	var paths []string
+	for _, p := range tree.Pointers() {
+		paths = append(paths, p.String())
+	}
+
+	if len(paths) != 3 || paths[0] != "/root" || paths[1] != "/root/b" || paths[2] != "/root/c" {
+		t.Errorf("error, unexpected pointers after removal: %v", paths)
+	}
+
+	tree.Insert(newPointerFromRefs([]string{"root", "a"}))
+
+	if keys := tree.Find(newPointerFromRefs([]string{"root"})).keys; len(keys) != 3 || keys[2] != "a" {
+		t.Errorf("error, reinserted node not appended to keys: %v", keys)
+	}
+}
+
+func TestPointerStore(t *testing.T) {
+
+	store := NewPointerRegistry()
+	a, b := newPointerFromRefs([]string{"b"}), newPointerFromRefs([]string{"a"})
+	s1, s2 := NewSchema(), NewSchema()
+
+	store.Set(a, s1)
+
+	if store.Add(a, s2) {
+		t.Error("error, Add overwrote an existing schema")
+	}
+
+	store.Set(a, s2)
+
+	if sch, _ := store.Get(a); sch != s2 {
+		t.Error("error, Set did not overwrite the existing schema")
+	}
+
+	store.Add(b, s1)
+
+	if keys := store.Keys(); store.Len() != 2 || keys[0].String() != "/a" || keys[1].String() != "/b" {
+		t.Errorf("error, unexpected keys %v", keys)
+	}
+
+	visited := 0
+	store.Range(func(ptr Pointer, sch Schema) bool {
+		visited++
+		store.Delete(ptr)
+		return false
+	})
+
+	if visited != 1 || store.Len() != 1 || store.Delete(b) {
+		t.Errorf("error, Range visited %d schemas and left %d", visited, store.Len())
+	}
+}
+
+func TestResolve(t *testing.T) {
+
+	store := NewPointerRegistry()
+
+	for path, data := range map[string]string{
+		"/components/schemas/pkg.Block": `{"type":"object","properties":{"hash":{"type":"string"},"txs":{"type":"array","items":[{"$ref":"#/components/schemas/pkg.Tx"}]}}}`,
+		"/components/schemas/pkg.Tx":    `{"type":"object","properties":{"from":{"type":"string","pattern":"^0x"}}}`,
+		"/components/schemas/loop":      `{"$ref":"#/components/schemas/loop"}`,
+	} {
+		ptr, _ := NewPointer(path)
+		sch := NewSchema()
+
+		if err := sch.UnmarshalJSON([]byte(data)); err != nil {
+			t.Fatal(err)
+		}
+
+		store.Set(ptr, sch)
+	}
+
+	for path, expected := range map[string]string{
+		"#/components/schemas/pkg.Block/properties/hash":                               `{"type":"string"}`,
+		"/components/schemas/pkg.Block/properties/txs/items/0/properties/from":         `{"pattern":"^0x","type":"string"}`,
+		"/components/schemas/pkg.Block/properties/txs/items/0/properties/from/pattern": `"^0x"`,
+		"/components/schemas/pkg.Tx":                                                   `{"properties":{"from":{"pattern":"^0x","type":"string"}},"type":"object"}`,
+	} {
+		ptr, err := NewPointer(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := store.Resolve(ptr)
+		if err != nil {
+			t.Fatalf("error resolving %s: %v", path, err)
+		}
+
+		if data, _ := json.Marshal(v); string(data) != expected {
+			t.Errorf("error, %s resolved to %s instead of %s", path, data, expected)
+		}
+	}
+
+	for _, path := range []string{
+		"/components/schemas/pkg.Block/properties/number",
+		"/components/schemas/missing/properties/hash",
+		"/components/schemas/loop/properties/x",
+		"/components/schemas/pkg.Block/properties/txs/items/-1",
+		"/components/schemas/pkg.Block/properties/txs/items/1",
+		"/components/schemas/pkg.Block/type/x",
+	} {
+		ptr, _ := NewPointer(path)
+
+		if v, err := store.Resolve(ptr); err == nil {
+			t.Errorf("error, %s resolved to %v", path, v)
+		}
+	}
+}
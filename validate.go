@@ -0,0 +1,304 @@
+package openrpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ValidationError is a problem found in a document; Path locates it, e.g. methods/getBlock/params/0
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// ValidationErrors lists all the problems found in a document
+type ValidationErrors []*ValidationError
+
+func (errs ValidationErrors) Error() string {
+
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = e.Error()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+var openrpcVersion = regexp.MustCompile(`^1\.\d+\.\d+$`)
+
+// Validate checks the fields required by the openrpc specification, the uniqueness of method and param names,
+// that every local reference in the document resolves, that the values of example pairings match the schemas
+// of their methods and that the document conforms to MetaSchema; it returns ValidationErrors if anything is wrong
+func (doc *DocumentSpec1) Validate() error {
+
+	v := &validator{}
+
+	if !openrpcVersion.MatchString(doc.OpenRPC) {
+		v.add("openrpc", "version %q is not a valid 1.x.x openrpc version", doc.OpenRPC)
+	}
+
+	if doc.Info == nil {
+		v.add("info", "is required")
+	} else {
+		v.required("info/title", doc.Info.Title)
+		v.required("info/version", doc.Info.Version)
+
+		if doc.Info.License != nil {
+			v.required("info/license/name", doc.Info.License.Name)
+		}
+	}
+
+	for i, s := range doc.Servers {
+		v.server(fmt.Sprintf("servers/%d", i), s)
+	}
+
+	v.externalDocs("externalDocs", doc.ExternalDocs)
+
+	if doc.Methods == nil {
+		v.add("methods", "is required")
+	}
+
+	names := map[string]bool{}
+
+	var unresolved ValidationErrors
+
+	for i, m := range doc.Methods {
+		path := fmt.Sprintf("methods/%d", i)
+
+		if m == nil {
+			v.add(path, "is null")
+			continue
+		}
+
+		if m.Name == "" {
+			v.add(path+"/name", "is required")
+		} else if names[m.Name] {
+			v.add(path+"/name", "method %s is declared more than once", m.Name)
+		}
+		names[m.Name] = true
+
+		// methods are checked with their references to components resolved; the references that do not resolve
+		// are reported with the other references of the document
+		resolved, err := doc.ResolveMethod(m)
+		if err != nil {
+			unresolved = append(unresolved, &ValidationError{Path: path, Message: err.Error()})
+			continue
+		}
+
+		v.method(path, m, resolved)
+	}
+
+	if doc.Components != nil {
+		v.components(doc.Components)
+	}
+
+	if len(v.errs) == 0 {
+		v.refs(doc)
+	}
+
+	// references that resolve, but not to a component of their section, e.g. a param referencing a schema
+	if len(v.errs) == 0 {
+		v.errs = append(v.errs, unresolved...)
+	}
+
+	// example values are only checked against schemas whose references all resolve
+	if len(v.errs) == 0 {
+		v.allExamples(doc)
+	}
+
+	// the json form of the document is checked against the meta-schema last, the checks above tell more precisely
+	// what is wrong; fields the document does not decode into are only found by ValidateDocument
+	if len(v.errs) == 0 {
+		v.metaSchema(doc)
+	}
+
+	if len(v.errs) > 0 {
+		return v.errs
+	}
+
+	return nil
+}
+
+type validator struct {
+	errs ValidationErrors
+}
+
+func (v *validator) metaSchema(doc *DocumentSpec1) {
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		v.add("", "cannot encode document: %v", err)
+		return
+	}
+
+	v.errs = append(v.errs, metaSchemaErrors(data)...)
+}
+
+func (v *validator) add(path, format string, args ...interface{}) {
+	v.errs = append(v.errs, &ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) required(path, value string) {
+	if value == "" {
+		v.add(path, "is required")
+	}
+}
+
+func (v *validator) server(path string, s *Server) {
+
+	if s == nil {
+		v.add(path, "is null")
+		return
+	}
+
+	v.required(path+"/name", s.Name)
+	v.required(path+"/url", s.URL)
+}
+
+func (v *validator) externalDocs(path string, docs *ExternalDocs) {
+	if docs != nil {
+		v.required(path+"/url", docs.URL)
+	}
+}
+
+// method checks m, a method with its references to components resolved; raw is the method as declared,
+// the content descriptors it references are checked once with the components
+func (v *validator) method(path string, raw, m *Method) {
+
+	v.externalDocs(path+"/externalDocs", m.ExternalDocs)
+
+	switch m.ParamStructure {
+	case "", "by-name", "by-position", "either":
+	default:
+		v.add(path+"/paramStructure", "%q is not one of by-name, by-position or either", m.ParamStructure)
+	}
+
+	if m.Params == nil {
+		v.add(path+"/params", "is required")
+	}
+
+	params := map[string]bool{}
+	optional := false
+
+	for i, p := range m.Params {
+		ppath := fmt.Sprintf("%s/params/%d", path, i)
+
+		if p == nil {
+			v.add(ppath, "is null")
+			continue
+		}
+
+		if raw.Params[i].Ref == nil {
+			v.contentDescriptor(ppath, p)
+		}
+
+		if params[p.Name] {
+			v.add(ppath+"/name", "param %s is declared more than once", p.Name)
+		}
+		params[p.Name] = true
+
+		if p.Required && optional {
+			v.add(ppath, "required param %s follows an optional param", p.Name)
+		}
+		optional = optional || !p.Required
+	}
+
+	if m.Result == nil {
+		v.add(path+"/result", "is required")
+	} else if raw.Result.Ref == nil {
+		v.contentDescriptor(path+"/result", m.Result)
+	}
+
+	for i, t := range m.Tags {
+		v.required(fmt.Sprintf("%s/tags/%d/name", path, i), t.Name)
+	}
+
+	for i, s := range m.Servers {
+		v.server(fmt.Sprintf("%s/servers/%d", path, i), &s)
+	}
+
+	for i, e := range m.Errors {
+		v.required(fmt.Sprintf("%s/errors/%d/message", path, i), e.Message)
+	}
+
+	for i, l := range m.Links {
+		v.required(fmt.Sprintf("%s/links/%d/name", path, i), l.Name)
+	}
+}
+
+// components checks the required fields of the content descriptors and errors declared in components
+func (v *validator) components(c *Components) {
+
+	if c.ContentDescriptors != nil {
+		for _, name := range c.ContentDescriptors.Names() {
+			cd, _ := c.ContentDescriptors.ContentDescriptor(name)
+			v.contentDescriptor("components/contentDescriptors/"+name, cd)
+		}
+	}
+
+	if c.Errors != nil {
+		for _, name := range c.Errors.Names() {
+			e, _ := c.Errors.Error(name)
+			v.required("components/errors/"+name+"/message", e.Message)
+		}
+	}
+}
+
+func (v *validator) contentDescriptor(path string, cd *ContentDescriptor) {
+
+	v.required(path+"/name", cd.Name)
+
+	if cd.Schema == nil && cd.InlineSchema == nil {
+		v.add(path+"/schema", "is required")
+	}
+}
+
+// refs checks that every local $ref in the json form of the document resolves
+func (v *validator) refs(doc *DocumentSpec1) {
+
+	res, err := NewRefResolver(doc)
+	if err != nil {
+		v.add("", "cannot encode document: %v", err)
+		return
+	}
+
+	var walk func(path string, node interface{})
+
+	walk = func(path string, node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := n["$ref"].(string); ok && strings.HasPrefix(ref, "#") {
+				if _, err := res.Resolve(ref); err != nil {
+					v.add(path, "unresolved reference %s", ref)
+				}
+			}
+
+			keys := make([]string, 0, len(n))
+			for k := range n {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				walk(path+"/"+k, n[k])
+			}
+		case []interface{}:
+			for i, item := range n {
+				walk(fmt.Sprintf("%s/%d", path, i), item)
+			}
+		}
+	}
+
+	root, _ := res.Root().(map[string]interface{})
+
+	for _, section := range []string{"methods", "components"} {
+		walk(section, root[section])
+	}
+}
@@ -0,0 +1,236 @@
+package openrpc
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+
+	t.Run("valid", func(t *testing.T) {
+
+		doc, err := ParseDocument([]byte(diffOldDocument))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := doc.Validate(); err != nil {
+			t.Errorf("error, document should be valid: %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+
+		doc, err := ParseDocument([]byte(`{
+			"openrpc": "2.0",
+			"info": {"title": "test"},
+			"methods": [
+				{
+					"name": "a",
+					"params": [
+						{"name": "x", "schema": {}},
+						{"name": "x", "required": true, "schema": {"$ref": "#/components/schemas/Missing"}}
+					],
+					"result": {"name": "r", "schema": {}}
+				},
+				{"name": "a", "params": []}
+			]
+		}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{
+			`openrpc: version "2.0" is not a valid 1.x.x openrpc version`,
+			"info/version: is required",
+			"methods/0/params/1/name: param x is declared more than once",
+			"methods/0/params/1: required param x follows an optional param",
+			"methods/1/name: method a is declared more than once",
+			"methods/1/result: is required",
+		}
+
+		errs, ok := doc.Validate().(ValidationErrors)
+		if !ok || len(errs) != len(want) {
+			t.Fatalf("error, got %v instead of %v", errs, want)
+		}
+
+		for i, e := range errs {
+			if e.Error() != want[i] {
+				t.Errorf("error, got %v instead of %v", e, want[i])
+			}
+		}
+	})
+
+	t.Run("unresolvedReference", func(t *testing.T) {
+
+		doc, err := ParseDocument([]byte(`{
+			"openrpc": "1.2.6",
+			"info": {"title": "test", "version": "1"},
+			"methods": [
+				{"name": "a", "params": [], "result": {"name": "r", "schema": {"$ref": "#/components/schemas/Missing"}}}
+			]
+		}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = doc.Validate()
+		if err == nil || err.Error() != "methods/0/result/schema: unresolved reference #/components/schemas/Missing" {
+			t.Errorf("error, got %v instead of an unresolved reference", err)
+		}
+	})
+
+	// the schemas section exists, but has no Missing key
+	t.Run("unresolvedReferenceToComponent", func(t *testing.T) {
+
+		doc, err := ParseDocument([]byte(`{
+			"openrpc": "1.2.6",
+			"info": {"title": "test", "version": "1"},
+			"methods": [
+				{"name": "a", "params": [], "result": {"name": "r", "schema": {"$ref": "#/components/schemas/Missing"}}}
+			],
+			"components": {"schemas": {"Present": {"type": "string"}}}
+		}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = doc.Validate()
+		if err == nil || err.Error() != "methods/0/result/schema: unresolved reference #/components/schemas/Missing" {
+			t.Errorf("error, got %v instead of an unresolved reference", err)
+		}
+	})
+
+	t.Run("examples", func(t *testing.T) {
+
+		doc, err := ParseDocument([]byte(`{
+			"openrpc": "1.2.6",
+			"info": {"title": "test", "version": "1"},
+			"methods": [
+				{
+					"name": "get_tree",
+					"params": [
+						{"name": "depth", "required": true, "schema": {"type": "integer", "minimum": 1}},
+						{"name": "label", "schema": {"type": "string"}}
+					],
+					"result": {"name": "tree", "schema": {"$ref": "#/components/schemas/Tree"}},
+					"examples": [
+						{
+							"name": "valid",
+							"params": [{"name": "depth", "value": 2}],
+							"result": {"name": "tree", "value": {"value": 1, "children": [{"value": 2}]}}
+						},
+						{
+							"name": "stale",
+							"params": [{"name": "depth", "value": 0}, {"name": "label", "value": "root"}, {"name": "colour", "value": "red"}],
+							"result": {"name": "tree", "value": {"value": 1, "children": [{"value": "2"}]}}
+						},
+						{
+							"params": [{"value": 3}, {"value": 4}, {"value": 5}],
+							"result": {"name": "tree", "externalValue": "https://example.com/tree.json"}
+						}
+					]
+				},
+				{
+					"name": "find_tree",
+					"paramStructure": "by-name",
+					"params": [
+						{"name": "depth", "required": true, "schema": {"type": "integer", "minimum": 1}},
+						{"name": "label", "schema": {"type": "string"}}
+					],
+					"result": {"name": "tree", "schema": {"$ref": "#/components/schemas/Tree"}},
+					"examples": [
+						{
+							"name": "byName",
+							"params": [{"name": "label", "value": "root"}, {"name": "depth", "value": 0}]
+						},
+						{
+							"name": "missing",
+							"params": [{"name": "label", "value": "root"}]
+						}
+					]
+				}
+			],
+			"components": {
+				"schemas": {
+					"Tree": {
+						"type": "object",
+						"required": ["value"],
+						"properties": {
+							"value": {"type": "integer"},
+							"children": {"type": "array", "items": {"$ref": "#/components/schemas/Tree"}}
+						}
+					}
+				}
+			}
+		}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{
+			`methods/0/examples/1/params/0/value: example stale of method get_tree: param depth: value 0 does not match the schema: /: 0 must be less than or equal to 1.000000`,
+			`methods/0/examples/1/params/2: example stale of method get_tree: param colour is not declared`,
+			`methods/0/examples/1/result/value: example stale of method get_tree: result: value {"children":[{"value":"2"}],"value":1} does not match the schema: /children/0/value: "2" type should be integer, got string`,
+			`methods/0/examples/2/params/1/value: example #2 of method get_tree: param label: value 4 does not match the schema: /: 4 type should be string, got integer`,
+			`methods/0/examples/2/params/2: example #2 of method get_tree: param #2 is not declared`,
+			`methods/1/examples/0/params/1/value: example byName of method find_tree: param depth: value 0 does not match the schema: /: 0 must be less than or equal to 1.000000`,
+			`methods/1/examples/1/params: example missing of method find_tree: required param depth is missing`,
+		}
+
+		errs, ok := doc.Validate().(ValidationErrors)
+		if !ok || len(errs) != len(want) {
+			t.Fatalf("error, got %v instead of %v", errs, want)
+		}
+
+		for i, e := range errs {
+			if e.Error() != want[i] {
+				t.Errorf("error, got %v instead of %v", e, want[i])
+			}
+		}
+	})
+}
+
+func TestValidateDocument(t *testing.T) {
+
+	if err := ValidateDocument([]byte(diffOldDocument)); err != nil {
+		t.Errorf("error, document should be valid: %v", err)
+	}
+
+	for _, c := range []struct {
+		name     string
+		document string
+		want     string
+	}{
+		{
+			"unknownField",
+			`{"openrpc": "1.2.6", "info": {"title": "t", "version": "1", "colour": "red"}, "methods": [], "bogus": 1, "x-vendor": true}`,
+			"bogus: is not a field of the openrpc specification\ninfo/colour: is not a field of the openrpc specification",
+		},
+		{
+			"wrongType",
+			`{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": "none"}`,
+			"methods: type should be array, got string",
+		},
+		{
+			"wrongEnum",
+			`{"openrpc": "1.2.6", "info": {"title": "t", "version": "1"}, "methods": [
+				{"name": "a", "params": [], "result": {"name": "r", "schema": {}}, "paramStructure": "sideways"}
+			]}`,
+			`methods/0/paramStructure: "sideways" is not one of by-name, by-position or either`,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateDocument([]byte(c.document))
+			if _, ok := err.(ValidationErrors); !ok || err.Error() != c.want {
+				t.Errorf("error, got %v instead of %v", err, c.want)
+			}
+		})
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		err := ValidateDocumentYAML([]byte("openrpc: 1.2.6\ninfo:\n  title: t\n  version: \"1\"\nmethods: []\nbogus: 1\n"))
+		if err == nil || err.Error() != "bogus: is not a field of the openrpc specification" {
+			t.Errorf("error, got %v instead of an unknown field", err)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package openrpc
+
+import "errors"
+
+// WalkFunc is called for every node visited by a walk: ptr is the pointer of the node, sch the schema stored under it
+// (nil if there is none) and depth the distance from the tree the walk started from, whose children have depth 0.
+// A non-nil error stops the walk and is returned by it, except for SkipSubtree
+type WalkFunc func(ptr Pointer, sch Schema, depth int) error
+
+// SkipSubtree is returned by a WalkFunc to skip the descendants of the node it was called for;
+// it has no effect in a post-order walk, where descendants are visited first
+var SkipSubtree = errors.New("skip subtree")
+
+// Walk calls fn for every descendant of the tree in pre-order, visiting parents before their children
+// and siblings in insertion order; reg can be nil
+func (pt *PointerTree) Walk(reg *PointerStore, fn WalkFunc) error {
+	return pt.walk(reg, InsertionOrder, false, 0, fn)
+}
+
+// WalkPostOrder is like Walk, visiting children before their parents
+func (pt *PointerTree) WalkPostOrder(reg *PointerStore, fn WalkFunc) error {
+	return pt.walk(reg, InsertionOrder, true, 0, fn)
+}
+
+func (pt *PointerTree) walk(reg *PointerStore, order Ordering, postOrder bool, depth int, fn WalkFunc) error {
+
+	keys, nodes := pt.children(order)
+
+	for _, key := range keys {
+		node := nodes[key]
+
+		var sch Schema
+		if reg != nil {
+			sch, _ = reg.Get(node.ptr)
+		}
+
+		if !postOrder {
+			if err := fn(node.ptr, sch, depth); err == SkipSubtree {
+				continue
+			} else if err != nil {
+				return err
+			}
+		}
+
+		if err := node.walk(reg, order, postOrder, depth+1, fn); err != nil {
+			return err
+		}
+
+		if postOrder {
+			if err := fn(node.ptr, sch, depth); err != nil && err != SkipSubtree {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Walk calls fn in pre-order for every node under the unmarshalFrom pointer, visiting siblings in the order
+// they are marshaled. The registry is not locked while fn runs, so fn may modify it
+func (s *SchemaRegistry) Walk(fn WalkFunc) error {
+	return s.walk(false, fn)
+}
+
+// WalkPostOrder is like Walk, visiting children before their parents
+func (s *SchemaRegistry) WalkPostOrder(fn WalkFunc) error {
+	return s.walk(true, fn)
+}
+
+func (s *SchemaRegistry) walk(postOrder bool, fn WalkFunc) error {
+
+	s.mu.RLock()
+	tree, order := s.pTree.Find(s.unmarshalFrom), s.ordering
+	s.mu.RUnlock()
+
+	if tree == nil {
+		return errors.New("unmarshalFrom pointer points to nil tree")
+	}
+
+	return tree.walk(s.reg, order, postOrder, 0, fn)
+}
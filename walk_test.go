@@ -0,0 +1,119 @@
+package openrpc
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func walkTestTree() (*PointerTree, *PointerStore) {
+
+	tree := NewPointerTree(nil).
+		Insert(newPointerFromRefs([]string{"a", "x"})).
+		Insert(newPointerFromRefs([]string{"a", "y"})).
+		Insert(newPointerFromRefs([]string{"b"}))
+
+	store := NewPointerRegistry()
+	store.Set(newPointerFromRefs([]string{"a", "x"}), NewSchema())
+	store.Set(newPointerFromRefs([]string{"b"}), NewSchema())
+
+	return tree, store
+}
+
+func TestWalk(t *testing.T) {
+
+	var visited []string
+
+	record := func(skip string) WalkFunc {
+		return func(ptr Pointer, sch Schema, depth int) error {
+			node := strings.Repeat(".", depth) + ptr.String()
+			if sch != nil {
+				node += "*"
+			}
+			visited = append(visited, node)
+
+			if ptr.String() == skip {
+				return SkipSubtree
+			}
+			return nil
+		}
+	}
+
+	cases := []struct {
+		name     string
+		walk     func(*PointerTree, *PointerStore, WalkFunc) error
+		skip     string
+		expected []string
+	}{
+		{"preOrder", (*PointerTree).Walk, "", []string{"/a", "./a/x*", "./a/y", "/b*"}},
+		{"postOrder", (*PointerTree).WalkPostOrder, "", []string{"./a/x*", "./a/y", "/a", "/b*"}},
+		{"skipSubtree", (*PointerTree).Walk, "/a", []string{"/a", "/b*"}},
+		{"skipSubtreePostOrder", (*PointerTree).WalkPostOrder, "/a", []string{"./a/x*", "./a/y", "/a", "/b*"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			visited = nil
+
+			tree, store := walkTestTree()
+
+			if err := c.walk(tree, store, record(c.skip)); err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(visited, c.expected) {
+				t.Errorf("error, visited %v instead of %v", visited, c.expected)
+			}
+		})
+	}
+
+	t.Run("stop", func(t *testing.T) {
+		tree, store := walkTestTree()
+		stop := errors.New("stop")
+
+		count := 0
+		err := tree.Walk(store, func(Pointer, Schema, int) error {
+			count++
+			return stop
+		})
+
+		if err != stop || count != 1 {
+			t.Errorf("error, walk returned %v after %d nodes", err, count)
+		}
+	})
+}
+
+func TestRegistryWalk(t *testing.T) {
+
+	root, _ := NewPointer("/components/schemas")
+
+	reg, err := NewSchemaRegistry(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reg.SetOrdering(Alphabetical)
+
+	var names []string
+
+	err = reg.Walk(func(ptr Pointer, sch Schema, depth int) error {
+		if depth != 0 || sch == nil {
+			t.Errorf("error, unexpected node %s at depth %d", ptr, depth)
+		}
+
+		names = append(names, ptr.Refs()[len(ptr.Refs())-1])
+
+		// the registry can be modified during a walk
+		reg.Remove(ptr)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(names, ",") != "anything,bool,integer,number,string" || len(reg.Schemas()) != 0 {
+		t.Errorf("error, walked %v", names)
+	}
+}
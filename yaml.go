@@ -0,0 +1,236 @@
+package openrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Documents are converted to and from yaml through their json encoding, so that yaml output follows
+// the same (stable) key order as json output, and yaml input goes through the same decoding as json input
+
+// ParseDocumentYAML decodes a yaml encoded openrpc document
+func ParseDocumentYAML(data []byte) (*DocumentSpec1, error) {
+
+	doc := &DocumentSpec1{}
+
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+// MarshalDocumentYAML encodes a document as yaml
+func MarshalDocumentYAML(doc *DocumentSpec1) ([]byte, error) {
+
+	var b bytes.Buffer
+
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (doc *DocumentSpec1) MarshalYAML() (interface{}, error) {
+	return marshalYAML(doc)
+}
+
+func (doc *DocumentSpec1) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, doc)
+}
+
+func (c *Components) MarshalYAML() (interface{}, error) {
+	return marshalYAML(c)
+}
+
+func (c *Components) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, c)
+}
+
+func (s *SchemaRegistry) MarshalYAML() (interface{}, error) {
+	return marshalYAML(s)
+}
+
+func (s *SchemaRegistry) UnmarshalYAML(value *yaml.Node) error {
+	return unmarshalYAML(value, s)
+}
+
+// marshalYAML encodes v as json and converts the result to a yaml node, keeping the order of object keys
+func marshalYAML(v interface{}) (*yaml.Node, error) {
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	return jsonToNode(dec)
+}
+
+// unmarshalYAML converts a yaml node to json and decodes it into v
+func unmarshalYAML(value *yaml.Node, v interface{}) error {
+
+	var b bytes.Buffer
+
+	if err := nodeToJSON(&b, value); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(b.Bytes(), v)
+}
+
+func jsonToNode(dec *json.Decoder) (*yaml.Node, error) {
+
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if t == '{' {
+			node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, stringNode(key.(string)))
+			}
+
+			child, err := jsonToNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+
+		// closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+
+		return node, nil
+	case string:
+		return stringNode(t), nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(t)}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	default:
+		return nil, fmt.Errorf("unexpected json token %v", tok)
+	}
+}
+
+func stringNode(s string) *yaml.Node {
+
+	node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+
+	if strings.Contains(strings.TrimRight(s, "\n"), "\n") {
+		node.Style = yaml.LiteralStyle
+	}
+
+	return node
+}
+
+func nodeToJSON(w io.Writer, node *yaml.Node) error {
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			_, err := io.WriteString(w, "null")
+			return err
+		}
+		return nodeToJSON(w, node.Content[0])
+	case yaml.AliasNode:
+		return nodeToJSON(w, node.Alias)
+	case yaml.MappingNode:
+		io.WriteString(w, "{")
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				io.WriteString(w, ",")
+			}
+
+			var key string
+			if err := node.Content[i].Decode(&key); err != nil {
+				return fmt.Errorf("line %d: invalid key: %v", node.Content[i].Line, err)
+			}
+
+			k, _ := json.Marshal(key)
+			w.Write(k)
+			io.WriteString(w, ":")
+
+			if err := nodeToJSON(w, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+
+		_, err := io.WriteString(w, "}")
+		return err
+	case yaml.SequenceNode:
+		io.WriteString(w, "[")
+
+		for i, item := range node.Content {
+			if i > 0 {
+				io.WriteString(w, ",")
+			}
+
+			if err := nodeToJSON(w, item); err != nil {
+				return err
+			}
+		}
+
+		_, err := io.WriteString(w, "]")
+		return err
+	case yaml.ScalarNode:
+		switch node.Tag {
+		case "!!str", "!!timestamp", "!!binary":
+			data, _ := json.Marshal(node.Value)
+			_, err := w.Write(data)
+			return err
+		}
+
+		var v interface{}
+
+		if err := node.Decode(&v); err != nil {
+			return fmt.Errorf("line %d: %v", node.Line, err)
+		}
+
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", node.Line, err)
+		}
+
+		_, err = w.Write(data)
+		return err
+	default:
+		return errors.New("unexpected yaml node")
+	}
+}
@@ -0,0 +1,82 @@
+package openrpc
+
+import (
+	"testing"
+)
+
+const yamlDocument = `openrpc: 1.2.6
+info:
+  title: test
+  description: |
+    A multi line
+    description
+  version: 1.0.0
+methods:
+  - name: getBlock
+    params:
+      - name: number
+        required: true
+        schema:
+          type: integer
+          minimum: 0
+    result:
+      name: block
+      schema:
+        $ref: '#/components/schemas/Block'
+components:
+  schemas:
+    Block:
+      type: object
+      required:
+        - hash
+      properties:
+        number:
+          type: integer
+        hash:
+          type: string
+          pattern: ^0x[0-9a-f]{64}$
+`
+
+func TestYAML(t *testing.T) {
+
+	doc, err := ParseDocumentYAML([]byte(yamlDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.Info.Version != "1.0.0" || doc.Methods[0].Params[0].InlineSchema == nil {
+		t.Fatalf("error, document was not decoded: %+v", doc)
+	}
+
+	if ptr := doc.Methods[0].Result.Schema; ptr == nil || ptr.String() != "/components/schemas/Block" {
+		t.Errorf("error, got result schema %v instead of a reference to Block", ptr)
+	}
+
+	if _, ok := doc.Components.Schemas.reg.Get(newPointerFromRefs([]string{"components", "schemas", "Block"})); !ok {
+		t.Errorf("error, Block schema was not registered")
+	}
+
+	data, err := MarshalDocumentYAML(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(data) != yamlDocument {
+		t.Errorf("error, round trip changed the document:\n%s", data)
+	}
+
+	doc.Info.Contact = Contact{Name: "support", Email: "support@example.com"}
+
+	if data, err = MarshalDocumentYAML(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseDocumentYAML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed.Info.Contact != doc.Info.Contact {
+		t.Errorf("error, got contact %+v instead of %+v", parsed.Info.Contact, doc.Info.Contact)
+	}
+}